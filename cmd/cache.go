@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/haijima/cobrax"
+	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/log"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NewCacheCmd returns the cache command
+func NewCacheCmd(p *internal.CacheProfiler, v *viper.Viper, fs afero.Fs) *cobra.Command {
+	cacheCmd := &cobra.Command{}
+	cacheCmd.Use = "cache"
+	cacheCmd.Short = "Simulate caches for GET requests and show the hit ratio of each endpoint"
+	cacheCmd.Example = "  stool cache --file path/to/access.log --ttl 1s,1m --capacity 100,1000 --ignore_query _"
+	cacheCmd.Args = cobra.NoArgs
+	cacheCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runCache(cmd, v, fs, p)
+	}
+
+	cacheCmd.Flags().StringSlice("ttl", []string{"1s", "10s", "1m"}, "Comma-separated list of TTLs of the caches keyed on the full URI")
+	cacheCmd.Flags().IntSlice("capacity", []int{10, 100, 1000}, "Comma-separated list of capacities of the LRU caches keyed on the path parameter values")
+	cacheCmd.Flags().StringSlice("ignore_query", []string{}, "Comma-separated list of query keys excluded from the cache key. \"*\" excludes the whole query")
+	cacheCmd.Flags().Bool("invalidate", true, "Invalidate the cached responses when a POST, PUT, PATCH or DELETE request has the same resource ID")
+	cacheCmd.Flags().String("format", "table", "The output format {table|md|csv|tsv}")
+
+	return cacheCmd
+}
+
+func runCache(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.CacheProfiler) error {
+	matchingGroups := v.GetStringSlice("matching_groups")
+	timeFormat := v.GetString("time_format")
+	labels := v.GetStringMapString("log_labels")
+	filter := v.GetString("filter")
+	format := v.GetString("format")
+	capacities := v.GetIntSlice("capacity")
+
+	if format != "table" && format != "md" && format != "csv" && format != "tsv" {
+		return fmt.Errorf("format flag should be 'table', 'md', 'csv' or 'tsv'. but: %s", format)
+	}
+	ttls := make([]time.Duration, 0)
+	for _, s := range v.GetStringSlice("ttl") {
+		ttl, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("ttl flag should be durations like '10s' or '1m'. but: %s", s)
+		}
+		ttls = append(ttls, ttl)
+	}
+	for _, c := range capacities {
+		if c <= 0 {
+			return fmt.Errorf("capacity flag should be positive. but: %d", c)
+		}
+	}
+
+	f, err := cobrax.OpenOrStdIn(v.GetString("file"), fs, cobrax.WithStdin(cmd.InOrStdin()))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	logReader, err := log.NewLTSVReader(f, log.LTSVReadOpt{
+		MatchingGroups: matchingGroups,
+		TimeFormat:     timeFormat,
+		Labels:         labels,
+		Filter:         filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := p.Profile(logReader, internal.CacheOption{
+		TTLs:        ttls,
+		Capacities:  capacities,
+		IgnoreQuery: v.GetStringSlice("ignore_query"),
+		Invalidate:  v.GetBool("invalidate"),
+	})
+	if err != nil {
+		return err
+	}
+
+	printCache(cmd, result, format)
+	return nil
+}
+
+func printCache(cmd *cobra.Command, result *internal.Cache, format string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+
+	header := table.Row{"Endpoint", "Requests"}
+	for _, ttl := range result.TTLs {
+		header = append(header, fmt.Sprintf("TTL %s(%%)", formatTTL(ttl)))
+	}
+	for _, c := range result.Capacities {
+		header = append(header, fmt.Sprintf("LRU %d(%%)", c))
+	}
+	header = append(header, "Invalidations")
+	t.AppendHeader(header)
+
+	var requests, invalidations int
+	ttlHits := make([]int, len(result.TTLs))
+	lruHits := make([]int, len(result.Capacities))
+	for _, k := range result.Endpoints {
+		row := table.Row{k, humanize.Comma(int64(result.Requests[k]))}
+		for i, hit := range result.TTLHits[k] {
+			row = append(row, hitRatio(hit, result.Requests[k]))
+			ttlHits[i] += hit
+		}
+		for i, hit := range result.LRUHits[k] {
+			row = append(row, hitRatio(hit, result.Requests[k]))
+			lruHits[i] += hit
+		}
+		row = append(row, humanize.Comma(int64(result.Invalidations[k])))
+		t.AppendRow(row)
+		requests += result.Requests[k]
+		invalidations += result.Invalidations[k]
+	}
+
+	columnConfigs := make([]table.ColumnConfig, 0, len(header)-1)
+	for i := 2; i <= len(header); i++ {
+		columnConfigs = append(columnConfigs, table.ColumnConfig{Number: i, Align: text.AlignRight, AlignFooter: text.AlignRight})
+	}
+	t.SetColumnConfigs(columnConfigs)
+
+	if format == "csv" {
+		t.RenderCSV()
+	} else if format == "tsv" {
+		t.RenderTSV()
+	} else {
+		footer := table.Row{"Total", humanize.Comma(int64(requests))}
+		for _, hit := range ttlHits {
+			footer = append(footer, hitRatio(hit, requests))
+		}
+		for _, hit := range lruHits {
+			footer = append(footer, hitRatio(hit, requests))
+		}
+		footer = append(footer, humanize.Comma(int64(invalidations)))
+		t.AppendFooter(footer)
+		if format == "md" {
+			t.RenderMarkdown()
+		} else {
+			t.Render()
+		}
+	}
+}
+
+func hitRatio(hit, requests int) string {
+	if requests == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", float64(hit)/float64(requests)*100)
+}
+
+// formatTTL formats the duration without trailing zero units like "1m" instead of "1m0s"
+func formatTTL(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
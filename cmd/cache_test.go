@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/haijima/stool/internal"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCacheCmd(t *testing.T) {
+	p := internal.NewCacheProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewCacheCmd(p, v, fs)
+
+	assert.Equal(t, "cache", cmd.Name(), "NewCacheCmd() should return command named \"cache\". but: %q", cmd.Name())
+}
+
+func TestNewCacheCmd_Flag(t *testing.T) {
+	p := internal.NewCacheProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewCacheCmd(p, v, fs)
+	ttlFlag := cmd.Flags().Lookup("ttl")
+	capacityFlag := cmd.Flags().Lookup("capacity")
+	invalidateFlag := cmd.Flags().Lookup("invalidate")
+
+	assert.NotNil(t, ttlFlag, "cache command should have \"ttl\" flag")
+	assert.Equal(t, "stringSlice", ttlFlag.Value.Type(), "\"ttl\" flag is stringSlice")
+	assert.NotNil(t, capacityFlag, "cache command should have \"capacity\" flag")
+	assert.Equal(t, "intSlice", capacityFlag.Value.Type(), "\"capacity\" flag is intSlice")
+	assert.NotNil(t, invalidateFlag, "cache command should have \"invalidate\" flag")
+	assert.Equal(t, "true", invalidateFlag.DefValue, "\"invalidate\" flag is true by default")
+}
+
+func TestNewCacheCmd_RunE(t *testing.T) {
+	p := internal.NewCacheProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewCacheCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "csv")
+	v.Set("ttl", []string{"10s", "1m"})
+	v.Set("capacity", []int{1})
+	v.Set("matching_groups", []string{"^/api/users/(?P<id>[^/]+)$"})
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/users/1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:30 +0900\treq:GET /api/users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:31 +0900\treq:DELETE /api/users/1 HTTP/2.0\tstatus:204\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:32 +0900\treq:GET /api/users/1 HTTP/2.0\tstatus:404\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Endpoint,Requests,TTL 10s(%),TTL 1m(%),LRU 1(%),Invalidations\nGET ^/api/users/(?P<id>[^/]+)$,3,0.00,33.33,33.33,1\n", stdout.String())
+}
+
+func TestNewCacheCmd_RunE_invalidTTL(t *testing.T) {
+	p := internal.NewCacheProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewCacheCmd(p, v, fs)
+
+	v.Set("ttl", []string{"10"})
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.EqualError(t, err, "ttl flag should be durations like '10s' or '1m'. but: 10")
+}
+
+func Test_formatTTL(t *testing.T) {
+	assert.Equal(t, "500ms", formatTTL(500*time.Millisecond))
+	assert.Equal(t, "10s", formatTTL(10*time.Second))
+	assert.Equal(t, "1m", formatTTL(time.Minute))
+	assert.Equal(t, "1m30s", formatTTL(90*time.Second))
+	assert.Equal(t, "1h", formatTTL(time.Hour))
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/haijima/cobrax"
+	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/log"
+	"github.com/haijima/stool/internal/openapi"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/exp/maps"
+)
+
+// NewCoverageCmd returns the coverage command
+func NewCoverageCmd(p *internal.ParamProfiler, v *viper.Viper, fs afero.Fs) *cobra.Command {
+	coverageCmd := &cobra.Command{}
+	coverageCmd.Use = "coverage"
+	coverageCmd.Short = "Compare the access log with an OpenAPI specification"
+	coverageCmd.Example = "  stool coverage --file path/to/access.log --spec openapi.yaml"
+	coverageCmd.Args = cobra.NoArgs
+	coverageCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runCoverage(cmd, v, fs, p)
+	}
+
+	coverageCmd.Flags().String("spec", "", "OpenAPI 3 specification file")
+	coverageCmd.Flags().String("format", "table", "The output format {table|md|csv|tsv}")
+
+	return coverageCmd
+}
+
+func runCoverage(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.ParamProfiler) error {
+	matchingGroups := v.GetStringSlice("matching_groups")
+	timeFormat := v.GetString("time_format")
+	labels := v.GetStringMapString("log_labels")
+	filter := v.GetString("filter")
+	format := v.GetString("format")
+	spec := v.GetString("spec")
+
+	if spec == "" {
+		return fmt.Errorf("spec flag is required")
+	}
+	if format != "table" && format != "md" && format != "csv" && format != "tsv" {
+		return fmt.Errorf("format flag should be 'table', 'md', 'csv' or 'tsv'. but: %s", format)
+	}
+
+	specFile, err := fs.Open(spec)
+	if err != nil {
+		return err
+	}
+	defer specFile.Close()
+	doc, err := openapi.Load(specFile)
+	if err != nil {
+		return err
+	}
+
+	f, err := cobrax.OpenOrStdIn(v.GetString("file"), fs, cobrax.WithStdin(cmd.InOrStdin()))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	logReader, err := log.NewLTSVReader(f, log.LTSVReadOpt{
+		MatchingGroups: internal.CoverageMatchingGroups(doc, matchingGroups),
+		TimeFormat:     timeFormat,
+		Labels:         labels,
+		Filter:         filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := p.Profile(logReader)
+	if err != nil {
+		return err
+	}
+
+	printCoverage(cmd, internal.NewCoverage(doc, result), format)
+	return nil
+}
+
+func printCoverage(cmd *cobra.Command, coverage *internal.Coverage, format string) {
+	rows := make([]table.Row, 0, len(coverage.Endpoints))
+	for _, e := range coverage.Endpoints {
+		var status string
+		switch e.Status {
+		case internal.Covered:
+			status = e.Status.String()
+			if len(e.UndocumentedQueryKeys) > 0 || len(e.UndeclaredStatuses) > 0 {
+				status = color.YellowString(status)
+			}
+		case internal.NotExercised:
+			status = color.HiBlackString(e.Status.String())
+		case internal.Undocumented:
+			status = color.RedString(e.Status.String())
+		}
+
+		queryKeys := maps.Keys(e.UndocumentedQueryKeys)
+		slices.Sort(queryKeys)
+		for i, qk := range queryKeys {
+			queryKeys[i] = fmt.Sprintf("%s(%s)", qk, humanize.Comma(int64(e.UndocumentedQueryKeys[qk])))
+		}
+		statuses := maps.Keys(e.UndeclaredStatuses)
+		slices.Sort(statuses)
+		statusStrs := make([]string, 0, len(statuses))
+		for _, s := range statuses {
+			statusStrs = append(statusStrs, fmt.Sprintf("%s(%s)", strconv.Itoa(s), humanize.Comma(int64(e.UndeclaredStatuses[s]))))
+		}
+
+		rows = append(rows, table.Row{
+			e.Method,
+			e.Path,
+			status,
+			humanize.Comma(int64(e.Count)),
+			strings.Join(queryKeys, " "),
+			strings.Join(statusStrs, " "),
+		})
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+	t.AppendHeader(table.Row{"Method", "Path", "Coverage", "Count", "Undocumented query", "Undeclared status"})
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 4, Align: text.AlignRight}})
+	t.AppendRows(rows)
+
+	switch format {
+	case "table":
+		t.Render()
+	case "md":
+		t.RenderMarkdown()
+	case "csv":
+		t.RenderCSV()
+	case "tsv":
+		t.RenderTSV()
+	}
+
+	if format == "table" || format == "md" {
+		documented, exercised := coverage.Operations()
+		if documented > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "\nOperations: %d / %d exercised (%s)\n", exercised, documented, emphasisPercentage(exercised, documented))
+		}
+	}
+}
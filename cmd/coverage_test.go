@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/haijima/stool/internal"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCoverageCmd(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewCoverageCmd(p, v, fs)
+
+	assert.Equal(t, "coverage", cmd.Name(), "NewCoverageCmd() should return command named \"coverage\". but: %q", cmd.Name())
+}
+
+func TestNewCoverageCmd_Flag(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewCoverageCmd(p, v, fs)
+	specFlag := cmd.Flags().Lookup("spec")
+	formatFlag := cmd.Flags().Lookup("format")
+
+	assert.True(t, cmd.HasAvailableFlags(), "coverage command should have available flag")
+	assert.NotNil(t, specFlag, "coverage command should have \"spec\" flag")
+	assert.Equal(t, "string", specFlag.Value.Type(), "\"spec\" flag is string")
+	assert.NotNil(t, formatFlag, "coverage command should have \"format\" flag")
+	assert.Equal(t, "string", formatFlag.Value.Type(), "\"format\" flag is string")
+}
+
+func Test_CoverageCmd_RunE(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewCoverageCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("spec", "openapi.yaml")
+	v.Set("format", "csv")
+	_ = afero.WriteFile(fs, "openapi.yaml", []byte("openapi: 3.0.0\ninfo: {title: test, version: \"1\"}\npaths:\n  /:\n    get:\n      responses:\n        \"200\": {description: OK}\n  /initialize:\n    post:\n      responses:\n        \"200\": {description: OK}\n"), 0777)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET /?q=1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/trend HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Method,Path,Coverage,Count,Undocumented query,Undeclared status\nGET,/,covered,1,q(1),\nPOST,/initialize,not exercised,0,,\nGET,/api/trend,undocumented,1,,\n", stdout.String())
+}
+
+func Test_CoverageCmd_RunE_without_spec(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewCoverageCmd(p, v, fs)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.ErrorContains(t, err, "spec flag is required")
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/haijima/cobrax"
+	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/log"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NewDuplicateCmd returns the duplicate command
+func NewDuplicateCmd(p *internal.DuplicateProfiler, v *viper.Viper, fs afero.Fs) *cobra.Command {
+	duplicateCmd := &cobra.Command{}
+	duplicateCmd.Use = "duplicate"
+	duplicateCmd.Aliases = []string{"retry"}
+	duplicateCmd.Short = "Show the requests repeated by the same user within a short time"
+	duplicateCmd.Example = "  stool duplicate --file path/to/access.log --window 5s"
+	duplicateCmd.Args = cobra.NoArgs
+	duplicateCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runDuplicate(cmd, v, fs, p)
+	}
+
+	duplicateCmd.Flags().Duration("window", 5*time.Second, "Requests with the same method and URI within the window after the previous one are duplicates")
+	duplicateCmd.Flags().String("format", "table", "The output format {table|md|csv|tsv}")
+	duplicateCmd.Flags().Duration("idle_timeout", 0, "Start a new session when a user is idle longer than the timeout. 0 means no timeout")
+	duplicateCmd.Flags().Bool("uidgot_session", true, "Start a session for a user who is first seen via uidgot")
+
+	return duplicateCmd
+}
+
+func runDuplicate(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.DuplicateProfiler) error {
+	matchingGroups := v.GetStringSlice("matching_groups")
+	timeFormat := v.GetString("time_format")
+	labels := v.GetStringMapString("log_labels")
+	filter := v.GetString("filter")
+	format := v.GetString("format")
+	window := v.GetDuration("window")
+	sessionOpt := internal.SessionOption{
+		IdleTimeout:   v.GetDuration("idle_timeout"),
+		StartOnUidGot: v.GetBool("uidgot_session"),
+	}
+
+	if window <= 0 {
+		return fmt.Errorf("window flag should be positive. but: %s", window)
+	}
+	if format != "table" && format != "md" && format != "csv" && format != "tsv" {
+		return fmt.Errorf("format flag should be 'table', 'md', 'csv' or 'tsv'. but: %s", format)
+	}
+
+	f, err := cobrax.OpenOrStdIn(v.GetString("file"), fs, cobrax.WithStdin(cmd.InOrStdin()))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	logReader, err := log.NewLTSVReader(f, log.LTSVReadOpt{
+		MatchingGroups: matchingGroups,
+		TimeFormat:     timeFormat,
+		Labels:         labels,
+		Filter:         filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := p.Profile(logReader, window, sessionOpt)
+	if err != nil {
+		return err
+	}
+
+	printDuplicate(cmd, result, format)
+	if format == "table" || format == "md" {
+		printGapHistogram(cmd, result)
+	}
+	return nil
+}
+
+func printDuplicate(cmd *cobra.Command, result *internal.Duplicate, format string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+	t.AppendHeader(table.Row{"Endpoint", "Requests", "Duplicates", "Duplicates(%)", "After error", "After error(%)", "Gap P50", "Gap P90", "Gap max"})
+	for _, k := range result.Endpoints {
+		row := table.Row{k, humanize.Comma(int64(result.Count[k])), humanize.Comma(int64(result.Duplicates[k])),
+			fmt.Sprintf("%.2f", float64(result.Duplicates[k])/float64(result.Count[k])*100)}
+		if gaps := result.Gaps[k]; len(gaps) > 0 {
+			row = append(row, humanize.Comma(int64(result.AfterError[k])),
+				fmt.Sprintf("%.2f", float64(result.AfterError[k])/float64(result.Duplicates[k])*100),
+				internal.DurationQuantile(gaps, 0.5), internal.DurationQuantile(gaps, 0.9), gaps[len(gaps)-1])
+		} else {
+			row = append(row, "0", "-", "-", "-", "-")
+		}
+		t.AppendRow(row)
+	}
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 2, Align: text.AlignRight}, {Number: 3, Align: text.AlignRight}, {Number: 4, Align: text.AlignRight}, {Number: 5, Align: text.AlignRight}, {Number: 6, Align: text.AlignRight}, {Number: 7, Align: text.AlignRight}, {Number: 8, Align: text.AlignRight}, {Number: 9, Align: text.AlignRight}})
+
+	if format == "csv" {
+		t.RenderCSV()
+	} else if format == "tsv" {
+		t.RenderTSV()
+	} else if format == "table" {
+		t.Render()
+	} else if format == "md" {
+		t.RenderMarkdown()
+	}
+}
+
+// printGapHistogram prints the distribution of the time since the previous request of all duplicates.
+// The window is split into buckets of at least 1 second because the time of the access log is usually in seconds.
+func printGapHistogram(cmd *cobra.Command, result *internal.Duplicate) {
+	gaps := result.AllGaps()
+	if len(gaps) == 0 {
+		return
+	}
+	width := max(time.Second, result.Window/10)
+	counts := make([]int, int((result.Window+width-1)/width))
+	maxCount := 0
+	for _, g := range gaps {
+		i := min(int(g/width), len(counts)-1)
+		counts[i]++
+		maxCount = max(maxCount, counts[i])
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "\nGap distribution (Duplicates: %s)\n", humanize.Comma(int64(len(gaps))))
+	labels := make([]string, len(counts))
+	labelWidth := 0
+	for i := range counts {
+		labels[i] = fmt.Sprintf("[%s, %s)", time.Duration(i)*width, time.Duration(i+1)*width)
+		if i == len(counts)-1 {
+			labels[i] = fmt.Sprintf("[%s, %s]", time.Duration(i)*width, result.Window)
+		}
+		labelWidth = max(labelWidth, len(labels[i]))
+	}
+	for i, c := range counts {
+		bar := strings.Repeat("█", c*histogramWidth/maxCount)
+		fmt.Fprintf(cmd.OutOrStdout(), "%-*s %s %s\n", labelWidth, labels[i], color.CyanString(bar), humanize.Comma(int64(c)))
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/haijima/stool/internal"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDuplicateCmd(t *testing.T) {
+	p := internal.NewDuplicateProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewDuplicateCmd(p, v, fs)
+
+	assert.Equal(t, "duplicate", cmd.Name(), "NewDuplicateCmd() should return command named \"duplicate\". but: %q", cmd.Name())
+}
+
+func TestNewDuplicateCmd_RunE(t *testing.T) {
+	p := internal.NewDuplicateProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewDuplicateCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "csv")
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:POST /api/orders HTTP/2.0\tstatus:499\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:01 +0900\treq:POST /api/orders HTTP/2.0\tstatus:201\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:09 +0900\treq:POST /api/orders HTTP/2.0\tstatus:201\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Endpoint,Requests,Duplicates,Duplicates(%),After error,After error(%),Gap P50,Gap P90,Gap max\nPOST /api/orders,3,1,33.33,1,100.00,1s,1s,1s\n", stdout.String())
+}
+
+func TestNewDuplicateCmd_RunE_histogram(t *testing.T) {
+	p := internal.NewDuplicateProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewDuplicateCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("window", "2s")
+	v.Set("no_color", true)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:00 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "\nGap distribution (Duplicates: 2)\n[0s, 1s) ████████████████████████████████████████ 1\n[1s, 2s] ████████████████████████████████████████ 1\n")
+}
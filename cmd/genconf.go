@@ -1,12 +1,19 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"slices"
+	"strings"
 
+	"github.com/fatih/color"
 	"github.com/haijima/cobrax"
 	"github.com/haijima/epf"
+	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/nginx"
+	"github.com/haijima/stool/internal/openapi"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -18,8 +25,9 @@ func NewGenConfCmd(v *viper.Viper, fs afero.Fs) *cobra.Command {
 	genConfCmd := &cobra.Command{}
 	genConfCmd.Use = "genconf"
 	genConfCmd.Short = "Generate configuration file"
-	genConfCmd.Example = "  stool genconf --format yaml > .stool.yaml"
+	genConfCmd.Example = "  stool genconf --format yaml > .stool.yaml\n  stool genconf --config .stool.yaml --profile webapp --dir ./webapp > new.stool.yaml\n  stool genconf --config .stool.yaml --update\n  stool genconf --openapi openapi.yaml > .stool.yaml\n  stool genconf --nginx-conf /etc/nginx/nginx.conf > .stool.yaml"
 	genConfCmd.Args = cobra.NoArgs
+	genConfCmd.Annotations = map[string]string{profileWriterAnnotation: "true"}
 	genConfCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		return runGenConf(cmd, v, fs)
 	}
@@ -28,38 +36,311 @@ func NewGenConfCmd(v *viper.Viper, fs afero.Fs) *cobra.Command {
 	genConfCmd.Flags().StringP("pattern", "p", "./...", "The pattern to analyze")
 	genConfCmd.Flags().String("format", "yaml", "The output format {toml|yaml|json|flag}")
 	genConfCmd.Flags().Bool("capture-group-name", false, "Add names to captured groups like \"(?P<name>pattern)\"")
+	genConfCmd.Flags().String("openapi", "", "OpenAPI 3 specification file to extract the endpoints from instead of Go source code")
+	genConfCmd.Flags().String("nginx-conf", "", "nginx configuration file to extract the location blocks from instead of Go source code")
+	genConfCmd.Flags().Bool("update", false, "Update matching_groups of the config file given by --config in place")
+
+	genConfCmd.MarkFlagsMutuallyExclusive("openapi", "nginx-conf")
 
 	return genConfCmd
 }
 
-func runGenConf(cmd *cobra.Command, v *viper.Viper, _ afero.Fs) error {
+func runGenConf(cmd *cobra.Command, v *viper.Viper, fs afero.Fs) error {
 	dir := v.GetString("dir")
 	pattern := v.GetString("pattern")
 	format := v.GetString("format")
+	profile := v.GetString("profile")
+	update := v.GetBool("update")
+	configFile := v.GetString("config")
 	if format != "toml" && format != "yaml" && format != "json" {
 		return fmt.Errorf("invalid format: %s", format)
 	}
+	if update && configFile == "" {
+		return fmt.Errorf("--update requires the config file given by --config")
+	}
 
-	flags := cobrax.GetFlags(cmd.Root())
-	matchingGroups, err := getMatchingGroups(dir, pattern)
+	var matchingGroups []string
+	var err error
+	if openapiFile := v.GetString("openapi"); openapiFile != "" {
+		matchingGroups, err = getMatchingGroupsFromOpenAPI(fs, openapiFile)
+	} else if nginxConf := v.GetString("nginx_conf"); nginxConf != "" {
+		matchingGroups, err = getMatchingGroupsFromNginxConf(fs, nginxConf)
+	} else {
+		matchingGroups, err = getMatchingGroups(dir, pattern)
+	}
 	if err != nil {
 		return err
 	}
-	flags["matching_groups"] = matchingGroups
+
+	if update {
+		return updateConfigFile(cmd, fs, configFile, profile, matchingGroups)
+	}
+
+	var conf map[string]any
+	if profile != "" {
+		// Emit into the named profile of the existing config file
+		loadedFile, err := usedConfigFile(cmd, v, fs)
+		if err != nil {
+			return err
+		}
+		conf, err = readConfigFile(fs, loadedFile)
+		if err != nil {
+			return err
+		}
+		profiles, _ := conf["profiles"].(map[string]any)
+		if profiles == nil {
+			profiles = make(map[string]any)
+		}
+		p, _ := profiles[profile].(map[string]any)
+		if p == nil {
+			p = make(map[string]any)
+		}
+		p["matching_groups"] = matchingGroups
+		profiles[profile] = p
+		conf["profiles"] = profiles
+	} else {
+		conf = cobrax.GetFlags(cmd.Root())
+		conf["matching_groups"] = matchingGroups
+	}
 
 	switch format {
 	case "toml":
-		return toml.NewEncoder(cmd.OutOrStdout()).Encode(flags)
+		return toml.NewEncoder(cmd.OutOrStdout()).Encode(conf)
 	case "yaml":
-		return yaml.NewEncoder(cmd.OutOrStdout()).Encode(flags)
+		return yaml.NewEncoder(cmd.OutOrStdout()).Encode(conf)
 	case "json":
 		enc := json.NewEncoder(cmd.OutOrStdout())
 		enc.SetIndent("", "  ")
-		return enc.Encode(flags)
+		return enc.Encode(conf)
+	}
+	return nil
+}
+
+// usedConfigFile returns the config file loaded by cobrax, or an empty string when no config file is used.
+// Without --config, cobrax merges every config file found in its default paths and leaves the last path it tried
+// in ConfigFileUsed, so the config files are looked up again by cobrax without merging to get the first one found.
+func usedConfigFile(cmd *cobra.Command, v *viper.Viper, fs afero.Fs) (string, error) {
+	if v.GetString("config") != "" {
+		return v.ConfigFileUsed(), nil
+	}
+	found := viper.New()
+	found.SetFs(fs)
+	if err := cobrax.BindConfigs(found, cmd.Root().Name(), cobrax.WithMergeConfig(false)); err != nil {
+		return "", err
+	}
+	if exists, _ := afero.Exists(fs, found.ConfigFileUsed()); !exists {
+		return "", nil
+	}
+	return found.ConfigFileUsed(), nil
+}
+
+// readConfigFile reads the config file into a map. It returns an empty map when fileName is empty.
+func readConfigFile(fs afero.Fs, fileName string) (map[string]any, error) {
+	conf := make(map[string]any)
+	if fileName == "" {
+		return conf, nil
+	}
+	b, err := afero.ReadFile(fs, fileName)
+	if err != nil {
+		return nil, err
+	}
+	switch ext := strings.TrimPrefix(filepath.Ext(fileName), "."); ext {
+	case "toml":
+		err = toml.Unmarshal(b, &conf)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(b, &conf)
+	case "json":
+		err = json.Unmarshal(b, &conf)
+	default:
+		return nil, fmt.Errorf("unsupported config file type: %s", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if conf == nil { // empty file
+		conf = make(map[string]any)
+	}
+	return conf, nil
+}
+
+const removedMatchingGroupComment = "# not found in the source"
+
+// updateConfigFile merges discovered matching groups into the config file and prints what changed.
+// Comments are preserved and patterns not found in the source are annotated only for YAML.
+func updateConfigFile(cmd *cobra.Command, fs afero.Fs, fileName, profile string, discovered []string) error {
+	b, err := afero.ReadFile(fs, fileName)
+	if err != nil {
+		return err
+	}
+	stat, err := fs.Stat(fileName)
+	if err != nil {
+		return err
+	}
+
+	var updated []byte
+	var merged []internal.MatchingGroup
+	ext := strings.TrimPrefix(filepath.Ext(fileName), ".")
+	if ext == "yaml" || ext == "yml" {
+		updated, merged, err = updateYAMLConfig(b, profile, discovered)
+	} else {
+		updated, merged, err = updateConfigMap(fs, fileName, profile, discovered)
+	}
+	if err != nil {
+		return err
+	}
+	if err := afero.WriteFile(fs, fileName, updated, stat.Mode()); err != nil {
+		return err
+	}
+
+	printMatchingGroupsDiff(cmd, fileName, merged)
+	if ext != "yaml" && ext != "yml" {
+		if removed := countMatchingGroups(merged, internal.MatchingGroupRemoved); removed > 0 {
+			cmd.PrintErrln(color.YellowString(fmt.Sprintf("[Warning] %d patterns not found in the source are kept in %s without %q, which is written only to YAML files", removed, fileName, removedMatchingGroupComment)))
+		}
 	}
 	return nil
 }
 
+func updateYAMLConfig(b []byte, profile string, discovered []string) ([]byte, []internal.MatchingGroup, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, nil, err
+	}
+	if doc.Kind == 0 { // empty file
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	target := doc.Content[0]
+	if target.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("the config file should be a mapping at the top level")
+	}
+	if profile != "" {
+		target = yamlMappingValue(yamlMappingValue(target, "profiles", yaml.MappingNode), profile, yaml.MappingNode)
+	}
+	seq := yamlMappingValue(target, "matching_groups", yaml.SequenceNode)
+
+	current := make([]string, 0, len(seq.Content))
+	for _, n := range seq.Content {
+		current = append(current, n.Value)
+	}
+	merged := internal.MergeMatchingGroups(current, discovered)
+
+	// Reuse the existing nodes to keep their comments. Existing patterns appear in merged in the same order.
+	content := make([]*yaml.Node, 0, len(merged))
+	i := 0
+	for _, mg := range merged {
+		if mg.Status == internal.MatchingGroupAdded {
+			content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: mg.Pattern})
+			continue
+		}
+		n := seq.Content[i]
+		i++
+		if mg.Status == internal.MatchingGroupRemoved {
+			n.LineComment = removedMatchingGroupComment
+		} else if n.LineComment == removedMatchingGroupComment {
+			n.LineComment = ""
+		}
+		content = append(content, n)
+	}
+	seq.Content = content
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), merged, nil
+}
+
+// yamlMappingValue returns the value node of the key in the mapping node. The key is added if it does not exist.
+func yamlMappingValue(m *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			v := m.Content[i+1]
+			if v.Kind != kind { // e.g. "matching_groups:" without any value
+				v.Kind, v.Tag, v.Value = kind, "", ""
+			}
+			return v
+		}
+	}
+	v := &yaml.Node{Kind: kind}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, v)
+	return v
+}
+
+func updateConfigMap(fs afero.Fs, fileName, profile string, discovered []string) ([]byte, []internal.MatchingGroup, error) {
+	conf, err := readConfigFile(fs, fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	target := conf
+	if profile != "" {
+		profiles, _ := conf["profiles"].(map[string]any)
+		if profiles == nil {
+			profiles = make(map[string]any)
+			conf["profiles"] = profiles
+		}
+		target, _ = profiles[profile].(map[string]any)
+		if target == nil {
+			target = make(map[string]any)
+			profiles[profile] = target
+		}
+	}
+
+	current := make([]string, 0)
+	if mgs, ok := target["matching_groups"].([]any); ok {
+		for _, mg := range mgs {
+			current = append(current, fmt.Sprint(mg))
+		}
+	}
+	merged := internal.MergeMatchingGroups(current, discovered)
+	patterns := make([]string, 0, len(merged))
+	for _, mg := range merged {
+		patterns = append(patterns, mg.Pattern)
+	}
+	target["matching_groups"] = patterns
+
+	var buf bytes.Buffer
+	if strings.HasSuffix(fileName, ".toml") {
+		err = toml.NewEncoder(&buf).Encode(conf)
+	} else {
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(conf)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), merged, nil
+}
+
+func countMatchingGroups(merged []internal.MatchingGroup, status internal.MatchingGroupStatus) int {
+	n := 0
+	for _, mg := range merged {
+		if mg.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func printMatchingGroupsDiff(cmd *cobra.Command, fileName string, merged []internal.MatchingGroup) {
+	var added, removed int
+	for _, mg := range merged {
+		switch mg.Status {
+		case internal.MatchingGroupAdded:
+			added++
+			fmt.Fprintln(cmd.OutOrStdout(), color.GreenString("+ %s", mg.Pattern))
+		case internal.MatchingGroupRemoved:
+			removed++
+			fmt.Fprintln(cmd.OutOrStdout(), color.RedString("! %s (not found in the source)", mg.Pattern))
+		}
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s: %d added, %d not found in the source\n", fileName, added, removed)
+}
+
 func getMatchingGroups(dir, pattern string) ([]string, error) {
 	ext, err := epf.AutoExtractor(dir, pattern)
 	if err != nil {
@@ -78,3 +359,24 @@ func getMatchingGroups(dir, pattern string) ([]string, error) {
 	matchingGroups = slices.Compact(matchingGroups)
 	return matchingGroups, nil
 }
+
+func getMatchingGroupsFromOpenAPI(fs afero.Fs, fileName string) ([]string, error) {
+	f, err := fs.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	doc, err := openapi.Load(f)
+	if err != nil {
+		return nil, err
+	}
+	return doc.MatchingGroups(), nil
+}
+
+func getMatchingGroupsFromNginxConf(fs afero.Fs, fileName string) ([]string, error) {
+	directives, err := nginx.ParseConfig(fs, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return nginx.MatchingGroups(nginx.Locations(directives)), nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -37,5 +38,135 @@ func TestRunGenConf(t *testing.T) {
 	err := cmd.Execute()
 
 	require.NoError(t, err)
-	assert.Equal(t, "config: \"\"\nfile: \"\"\nfilter: \"\"\ngenconf:\n    capture_group_name: \"false\"\n    dir: ./testdata/src\n    format: yaml\n    pattern: ./...\nlog_labels: '[]'\nmatching_groups:\n    - ^/api/users/([^/]+)$\n    - ^/api/users$\n    - ^/api/items$\nno_color: \"false\"\nparam:\n    format: table\n    num: \"5\"\n    stat: \"false\"\n    type: all\nquiet: \"false\"\nscenario:\n    format: dot\n    palette: \"false\"\ntime_format: 02/Jan/2006:15:04:05 -0700\ntransition:\n    format: dot\ntrend:\n    format: table\n    interval: \"5\"\n    sort: '[sum:desc]'\nverbose: \"0\"\n", stdout.String())
+	assert.Equal(t, "cache:\n    capacity: '[10,100,1000]'\n    format: table\n    ignore_query: '[]'\n    invalidate: \"true\"\n    ttl: '[1s,10s,1m]'\nconfig: \"\"\ncoverage:\n    format: table\n    spec: \"\"\nduplicate:\n    format: table\n    idle_timeout: 0s\n    uidgot_session: \"true\"\n    window: 5s\nfile: \"\"\nfilter: \"\"\ngenconf:\n    capture_group_name: \"false\"\n    dir: ./testdata/src\n    format: yaml\n    nginx_conf: \"\"\n    openapi: \"\"\n    pattern: ./...\n    update: \"false\"\nlog_labels: '[]'\nmatching_groups:\n    - ^/api/users/([^/]+)$\n    - ^/api/users$\n    - ^/api/items$\nno_color: \"false\"\nopenapi:\n    api_version: 0.0.0\n    format: yaml\n    title: stool\nparam:\n    approx: \"false\"\n    buckets: \"10\"\n    capacity: \"1000\"\n    dist: \"false\"\n    format: table\n    idle_timeout: 0s\n    interval: \"5\"\n    latency: \"false\"\n    num: \"5\"\n    paging: \"false\"\n    stat: \"false\"\n    trend: \"\"\n    type: all\n    uidgot_session: \"true\"\nprofile: \"\"\nquiet: \"false\"\nreplay:\n    base_url: http://localhost:8080\n    concurrency: \"10\"\n    format: table\n    method: \x27[GET]\x27\n    speed: \"1\"\n    timeout: 10s\nresource:\n    format: table\n    num: \"5\"\n    stat: \"false\"\nscenario:\n    drop_active_at_end: 0s\n    drop_start_near_begin: 0s\n    drop_start_near_end: 0s\n    drop_start_near_end_ratio: \"0.05\"\n    format: dot\n    idle_timeout: 0s\n    out_dir: \"\"\n    package: scenario\n    palette: \"false\"\n    similarity: \"0\"\n    uidgot_session: \"false\"\ntime_format: 02/Jan/2006:15:04:05 -0700\ntransition:\n    format: dot\n    idle_timeout: 0s\n    uidgot_session: \"true\"\ntrend:\n    format: table\n    interval: \"5\"\n    sort: '[sum:desc]'\nverbose: \"0\"\n", stdout.String())
+}
+
+func TestRunGenConf_Profile(t *testing.T) {
+	v := viper.New()
+	fs := afero.NewOsFs()
+	confFile := filepath.Join(t.TempDir(), ".stool.yaml")
+	require.NoError(t, afero.WriteFile(fs, confFile, []byte("time_format: 02/Jan/2006:15:04:05\nprofiles:\n  admin:\n    matching_groups:\n      - ^/admin$\n  webapp:\n    log_labels:\n      uidset: uid\n    matching_groups:\n      - ^/old$\n"), 0644))
+	stdout := new(bytes.Buffer)
+	cmd := NewRootCmd(v, fs)
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"genconf", "--config", confFile, "--profile", "webapp", "--format", "yaml", "--dir", "./testdata/src"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, "profiles:\n    admin:\n        matching_groups:\n            - ^/admin$\n    webapp:\n        log_labels:\n            uidset: uid\n        matching_groups:\n            - ^/api/users/([^/]+)$\n            - ^/api/users$\n            - ^/api/items$\ntime_format: 02/Jan/2006:15:04:05\n", stdout.String())
+}
+
+func TestRunGenConf_Profile_DefaultConfig(t *testing.T) {
+	v, fs := createViperAndFs()
+	confFile, _ := filepath.Abs(".stool.yaml")
+	require.NoError(t, afero.WriteFile(fs, confFile, []byte("time_format: 02/Jan/2006:15:04:05\nprofiles:\n  admin:\n    matching_groups:\n      - ^/admin$\n"), 0644))
+	stdout := new(bytes.Buffer)
+	cmd := NewRootCmd(v, fs)
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"genconf", "--profile", "webapp", "--format", "yaml", "--dir", "./testdata/src"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, "profiles:\n    admin:\n        matching_groups:\n            - ^/admin$\n    webapp:\n        matching_groups:\n            - ^/api/users/([^/]+)$\n            - ^/api/users$\n            - ^/api/items$\ntime_format: 02/Jan/2006:15:04:05\n", stdout.String())
+}
+
+func TestRunGenConf_Profile_DefaultConfigs(t *testing.T) {
+	v, fs := createViperAndFs()
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	require.NoError(t, afero.WriteFile(fs, "/xdg/stool/config.yaml", []byte("profiles:\n  admin:\n    matching_groups:\n      - ^/admin$\n"), 0644))
+	confFile, _ := filepath.Abs(".stool.yaml")
+	require.NoError(t, afero.WriteFile(fs, confFile, []byte("time_format: 02/Jan/2006:15:04:05\n"), 0644))
+	stdout := new(bytes.Buffer)
+	cmd := NewRootCmd(v, fs)
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"genconf", "--profile", "webapp", "--format", "yaml", "--dir", "./testdata/src"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, "profiles:\n    admin:\n        matching_groups:\n            - ^/admin$\n    webapp:\n        matching_groups:\n            - ^/api/users/([^/]+)$\n            - ^/api/users$\n            - ^/api/items$\n", stdout.String())
+}
+
+func TestRunGenConf_Update(t *testing.T) {
+	v := viper.New()
+	fs := afero.NewOsFs()
+	confFile := filepath.Join(t.TempDir(), ".stool.yaml")
+	require.NoError(t, afero.WriteFile(fs, confFile, []byte("# stool config\ntime_format: 02/Jan/2006:15:04:05\nmatching_groups:\n  - ^/api/users/(?P<id>[0-9]+)$ # hand-tuned\n  - ^/api/old$\n"), 0644))
+	stdout := new(bytes.Buffer)
+	cmd := NewRootCmd(v, fs)
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"genconf", "--config", confFile, "--update", "--dir", "./testdata/src"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	b, _ := afero.ReadFile(fs, confFile)
+	assert.Equal(t, "# stool config\ntime_format: 02/Jan/2006:15:04:05\nmatching_groups:\n    - ^/api/users/(?P<id>[0-9]+)$ # hand-tuned\n    - ^/api/users$\n    - ^/api/items$\n    - ^/api/old$ # not found in the source\n", string(b))
+	assert.Equal(t, "+ ^/api/users$\n+ ^/api/items$\n! ^/api/old$ (not found in the source)\n"+confFile+": 2 added, 1 not found in the source\n", stdout.String())
+}
+
+func TestRunGenConf_Update_JSON(t *testing.T) {
+	v := viper.New()
+	fs := afero.NewOsFs()
+	confFile := filepath.Join(t.TempDir(), ".stool.json")
+	require.NoError(t, afero.WriteFile(fs, confFile, []byte(`{"profiles": {"webapp": {"matching_groups": ["^/api/items$"]}}}`), 0644))
+	cmd := NewRootCmd(v, fs)
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"genconf", "--config", confFile, "--profile", "webapp", "--update", "--dir", "./testdata/src"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	b, _ := afero.ReadFile(fs, confFile)
+	assert.JSONEq(t, `{"profiles": {"webapp": {"matching_groups": ["^/api/users/([^/]+)$", "^/api/users$", "^/api/items$"]}}}`, string(b))
+}
+
+func TestRunGenConf_Update_TOML(t *testing.T) {
+	v := viper.New()
+	fs := afero.NewOsFs()
+	confFile := filepath.Join(t.TempDir(), ".stool.toml")
+	require.NoError(t, afero.WriteFile(fs, confFile, []byte("matching_groups = ['^/api/items$', '^/api/old$']\n"), 0644))
+	stderr := new(bytes.Buffer)
+	cmd := NewRootCmd(v, fs)
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{"genconf", "--config", confFile, "--update", "--dir", "./testdata/src"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	b, _ := afero.ReadFile(fs, confFile)
+	assert.Equal(t, "matching_groups = ['^/api/users/([^/]+)$', '^/api/users$', '^/api/items$', '^/api/old$']\n", string(b))
+	assert.Equal(t, "[Warning] 1 patterns not found in the source are kept in "+confFile+" without \"# not found in the source\", which is written only to YAML files\n", stderr.String())
+}
+
+func TestRunGenConf_Update_NoConfig(t *testing.T) {
+	v, fs := createViperAndFs()
+	cmd := NewRootCmd(v, fs)
+	cmd.SetArgs([]string{"genconf", "--update", "--dir", "./testdata/src"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "--update requires")
+}
+
+func TestRunGenConf_OpenAPI(t *testing.T) {
+	v, fs := createViperAndFs()
+	require.NoError(t, afero.WriteFile(fs, "openapi.yaml", []byte("openapi: 3.0.0\ninfo:\n  title: test\n  version: 1.0.0\npaths:\n  /api/users/{id}:\n    get:\n      parameters:\n        - name: id\n          in: path\n          schema:\n            type: integer\n      responses: {}\n  /api/users/me:\n    get:\n      responses: {}\n"), 0644))
+	stdout := new(bytes.Buffer)
+	cmd := NewRootCmd(v, fs)
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"genconf", "--format", "yaml", "--openapi", "openapi.yaml"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "matching_groups:\n    - ^/api/users/me$\n    - ^/api/users/(?P<id>-?[0-9]+)$\n")
+}
+
+func TestRunGenConf_NginxConf(t *testing.T) {
+	v, fs := createViperAndFs()
+	require.NoError(t, afero.WriteFile(fs, "nginx.conf", []byte("server {\n    location / { proxy_pass http://app; }\n    location = /favicon.ico { }\n    location ~ ^/api/users/(\\d+)$ { proxy_pass http://app; }\n}\n"), 0644))
+	stdout := new(bytes.Buffer)
+	cmd := NewRootCmd(v, fs)
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"genconf", "--format", "yaml", "--nginx-conf", "nginx.conf"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "matching_groups:\n    - ^/favicon\\.ico$\n    - ^/api/users/(\\d+)$\n    - ^/\n")
 }
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/haijima/cobrax"
+	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// NewOpenAPICmd returns the openapi command
+func NewOpenAPICmd(p *internal.ParamProfiler, v *viper.Viper, fs afero.Fs) *cobra.Command {
+	openapiCmd := &cobra.Command{}
+	openapiCmd.Use = "openapi"
+	openapiCmd.Short = "Generate an OpenAPI document inferred from the access log"
+	openapiCmd.Example = "  stool openapi --file path/to/access.log --matching_groups \"^/users/(?P<id>[^/]+)$\" > openapi.yaml"
+	openapiCmd.Args = cobra.NoArgs
+	openapiCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runOpenAPI(cmd, v, fs, p)
+	}
+
+	openapiCmd.Flags().String("format", "yaml", "The output format {yaml|json}")
+	openapiCmd.Flags().String("title", "stool", "The title of the API")
+	openapiCmd.Flags().String("api_version", "0.0.0", "The version of the API")
+
+	return openapiCmd
+}
+
+func runOpenAPI(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.ParamProfiler) error {
+	matchingGroups := v.GetStringSlice("matching_groups")
+	timeFormat := v.GetString("time_format")
+	labels := v.GetStringMapString("log_labels")
+	filter := v.GetString("filter")
+	format := v.GetString("format")
+	title := v.GetString("title")
+	apiVersion := v.GetString("api_version")
+
+	if format != "yaml" && format != "json" {
+		return fmt.Errorf("format flag should be 'yaml' or 'json'. but: %s", format)
+	}
+
+	f, err := cobrax.OpenOrStdIn(v.GetString("file"), fs, cobrax.WithStdin(cmd.InOrStdin()))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	logReader, err := log.NewLTSVReader(f, log.LTSVReadOpt{
+		MatchingGroups: matchingGroups,
+		TimeFormat:     timeFormat,
+		Labels:         labels,
+		Filter:         filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := p.Profile(logReader)
+	if err != nil {
+		return err
+	}
+
+	doc := internal.NewOpenAPI(result, title, apiVersion)
+	if format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	}
+	enc := yaml.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent(2)
+	return enc.Encode(doc)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/haijima/stool/internal"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOpenAPICmd(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewOpenAPICmd(p, v, fs)
+
+	assert.Equal(t, "openapi", cmd.Name(), "NewOpenAPICmd() should return command named \"openapi\". but: %q", cmd.Name())
+}
+
+func TestNewOpenAPICmd_Flag(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewOpenAPICmd(p, v, fs)
+	formatFlag := cmd.Flags().Lookup("format")
+
+	assert.True(t, cmd.HasAvailableFlags(), "openapi command should have available flag")
+	assert.NotNil(t, formatFlag, "openapi command should have \"format\" flag")
+	assert.Equal(t, "string", formatFlag.Value.Type(), "\"format\" flag is string")
+}
+
+func Test_OpenAPICmd_RunE(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewOpenAPICmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("matching_groups", []string{"^/api/users/(?P<id>[0-9]+)$"})
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET /api/users/1?page=1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/users/2 HTTP/2.0\tstatus:404\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "openapi: 3.0.3\ninfo:\n  title: stool\n  version: 0.0.0\npaths:\n  /api/users/{id}:\n    get:\n      summary: 2 requests observed\n      parameters:\n        - name: id\n          in: path\n          required: true\n          schema:\n            type: integer\n        - name: page\n          in: query\n          schema:\n            type: integer\n      responses:\n        \"200\":\n          description: OK\n        \"404\":\n          description: Not Found\n", stdout.String())
+}
+
+func Test_OpenAPICmd_RunE_invalid_format(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewOpenAPICmd(p, v, fs)
+
+	v.Set("format", "xml")
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.ErrorContains(t, err, "format flag should be 'yaml' or 'json'")
+}
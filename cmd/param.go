@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"cmp"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -18,6 +20,7 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
 )
 
 // NewParamCmd returns the param command
@@ -34,7 +37,17 @@ func NewParamCmd(p *internal.ParamProfiler, v *viper.Viper, fs afero.Fs) *cobra.
 	paramCmd.Flags().StringP("type", "t", "all", "The type of the parameter {path|query|all}")
 	paramCmd.Flags().IntP("num", "n", 5, "The number of parameters to show")
 	paramCmd.Flags().Bool("stat", false, "Show statistics of the parameters")
-	paramCmd.Flags().String("format", "table", "The stat output format {table|md|csv|tsv}")
+	paramCmd.Flags().Bool("dist", false, "Show the inferred type of the parameters and the distribution of numeric ones")
+	paramCmd.Flags().Int("buckets", 10, "The number of histogram buckets of the distribution")
+	paramCmd.Flags().Bool("approx", false, "Track only the frequent values of each parameter to bound the memory usage")
+	paramCmd.Flags().Int("capacity", 1000, "The number of values tracked per parameter with --approx")
+	paramCmd.Flags().Bool("latency", false, "Show the response time of each parameter value. Requires the reqtime field in the log")
+	paramCmd.Flags().Bool("paging", false, "Show how deep users paginate with the paging query parameters like page, offset and limit")
+	paramCmd.Flags().Duration("idle_timeout", 0, "Start a new session when a user is idle longer than the timeout with --paging. 0 means no timeout")
+	paramCmd.Flags().Bool("uidgot_session", true, "Start a session for a user who is first seen via uidgot with --paging")
+	paramCmd.Flags().String("trend", "", "Show the counts over time of the top values of the parameter. e.g. \":id\" for a path parameter, \"?page\" for a query parameter")
+	paramCmd.Flags().IntP("interval", "i", 5, "time (in seconds) of the interval of --trend")
+	paramCmd.Flags().String("format", "table", "The stat output format {table|md|csv|tsv}, or the machine-readable output format {json|yaml}")
 
 	return paramCmd
 }
@@ -48,13 +61,46 @@ func runParam(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.Param
 	statFlg := v.GetBool("stat")
 	format := v.GetString("format")
 	matchingGroups := v.GetStringSlice("matching_groups")
+	dist := v.GetBool("dist")
+	buckets := v.GetInt("buckets")
+	approx := v.GetBool("approx")
+	capacity := v.GetInt("capacity")
+	latency := v.GetBool("latency")
+	paging := v.GetBool("paging")
+	trendParam := v.GetString("trend")
+	interval := v.GetInt("interval")
 
 	paramType = strings.ToLower(paramType)
 	if paramType != "path" && paramType != "query" && paramType != "all" {
 		return fmt.Errorf("type flag should be 'path', 'query' or 'all'. but: %s", paramType)
 	}
-	if format != "table" && format != "md" && format != "csv" && format != "tsv" {
-		return fmt.Errorf("format flag should be 'table', 'md', 'csv' or 'tsv'. but: %s", format)
+	if format != "table" && format != "md" && format != "csv" && format != "tsv" && format != "json" && format != "yaml" {
+		return fmt.Errorf("format flag should be 'table', 'md', 'csv', 'tsv', 'json' or 'yaml'. but: %s", format)
+	}
+	if buckets <= 0 {
+		return fmt.Errorf("buckets flag should be positive. but: %d", buckets)
+	}
+	if approx {
+		if capacity <= 0 {
+			return fmt.Errorf("capacity flag should be positive. but: %d", capacity)
+		}
+		if dist || format == "json" || format == "yaml" {
+			return fmt.Errorf("approx flag cannot be used with dist flag nor json/yaml format")
+		}
+	}
+	if latency && (approx || dist || format == "json" || format == "yaml") {
+		return fmt.Errorf("latency flag cannot be used with approx flag, dist flag nor json/yaml format")
+	}
+	if paging && (approx || dist || latency || format == "json" || format == "yaml") {
+		return fmt.Errorf("paging flag cannot be used with approx flag, dist flag, latency flag nor json/yaml format")
+	}
+	if trendParam != "" {
+		if approx || dist || latency || paging || format == "json" || format == "yaml" {
+			return fmt.Errorf("trend flag cannot be used with approx flag, dist flag, latency flag, paging flag nor json/yaml format")
+		}
+		if interval <= 0 {
+			return fmt.Errorf("interval flag should be positive. but: %d", interval)
+		}
 	}
 
 	f, err := cobrax.OpenOrStdIn(v.GetString("file"), fs, cobrax.WithStdin(cmd.InOrStdin()))
@@ -72,15 +118,75 @@ func runParam(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.Param
 		return err
 	}
 
+	if trendParam != "" {
+		result, err := p.ProfileTrend(logReader, trendParam, interval)
+		if err != nil {
+			return err
+		}
+		if len(result.Endpoints) == 0 {
+			return fmt.Errorf("no request has the parameter %q", trendParam)
+		}
+		printParamTrend(cmd, result, trendParam, num, format)
+		return nil
+	}
+	if paging {
+		result, err := p.ProfilePagination(logReader, internal.SessionOption{
+			IdleTimeout:   v.GetDuration("idle_timeout"),
+			StartOnUidGot: v.GetBool("uidgot_session"),
+		})
+		if err != nil {
+			return err
+		}
+		if statFlg {
+			printPaginationStat(cmd, result, format)
+		} else {
+			printPaginationResult(cmd, result, num)
+		}
+		return nil
+	}
+	if latency {
+		result, err := p.ProfileLatency(logReader)
+		if err != nil {
+			return err
+		}
+		if len(result.Endpoints) == 0 {
+			reqtimeLabel := cmp.Or(labels["reqtime"], "reqtime")
+			return fmt.Errorf("%q field is not found in the log. Use --log_labels reqtime=<label> to specify the label of the response time", reqtimeLabel)
+		}
+		if statFlg {
+			printParamLatencyStat(cmd, result, paramType, format)
+		} else {
+			printParamLatencyResult(cmd, result, paramType, num)
+		}
+		return nil
+	}
+	if approx {
+		result, err := p.ProfileApprox(logReader, capacity)
+		if err != nil {
+			return err
+		}
+		if statFlg {
+			printApproxParamStat(cmd, result, paramType, format)
+		} else {
+			printApproxParamResult(cmd, result, paramType, num)
+		}
+		return nil
+	}
+
 	result, err := p.Profile(logReader)
 	if err != nil {
 		return err
 	}
 
-	if statFlg {
-		printParamStat(cmd, result, paramType, format)
+	if !dist {
+		buckets = 0
+	}
+	if format == "json" || format == "yaml" {
+		return printParamReport(cmd, result, paramType, num, buckets, format)
+	} else if statFlg {
+		printParamStat(cmd, result, paramType, format, dist)
 	} else {
-		printParamResult(cmd, result, paramType, num, v.GetBool("quiet"))
+		printParamResult(cmd, result, paramType, num, buckets, v.GetBool("quiet"))
 	}
 	return nil
 }
@@ -90,7 +196,9 @@ type kv struct {
 	Value int
 }
 
-func printParamResult(cmd *cobra.Command, result *internal.Param, paramType string, displayNum int, quiet bool) {
+// printParamResult prints the top values of the parameters.
+// The type and the distribution of each parameter are printed as well if buckets is positive.
+func printParamResult(cmd *cobra.Command, result *internal.Param, paramType string, displayNum int, buckets int, quiet bool) {
 	for _, k := range result.Endpoints {
 		v := result.Count[k]
 		pathParams, hasPathParam := result.Path[k]
@@ -126,15 +234,15 @@ func printParamResult(cmd *cobra.Command, result *internal.Param, paramType stri
 		fmt.Fprintf(cmd.OutOrStdout(), "%s (Count: %s)\n", color.New(color.FgHiBlue, color.Underline).Sprint(k), emphasisInt(v))
 
 		if hasPathParam && (paramType == "path" || paramType == "all") {
-			printPathParamsResult(cmd, pathParams, result.PathName[k], displayNum, v)
+			printPathParamsResult(cmd, pathParams, result.PathName[k], displayNum, buckets, v)
 		}
 		if hasQuery && (paramType == "query" || paramType == "all") {
-			printQueryResult(cmd, result, displayNum, queryParams, k, v)
+			printQueryResult(cmd, result, displayNum, buckets, queryParams, k, v)
 		}
 	}
 }
 
-func printPathParamsResult(cmd *cobra.Command, pathParams []map[string]int, pathNames []string, displayNum int, v int) {
+func printPathParamsResult(cmd *cobra.Command, pathParams []map[string]int, pathNames []string, displayNum int, buckets int, v int) {
 	for i, vv := range pathParams {
 		ks := len(vv)
 		var paramName string
@@ -163,11 +271,14 @@ func printPathParamsResult(cmd *cobra.Command, pathParams []map[string]int, path
 		if ks > displayNum {
 			fmt.Fprintf(cmd.OutOrStdout(), "\t\t... and %s more\n", humanize.Comma(int64(ks-displayNum)))
 		}
+		if buckets > 0 {
+			printParamDistribution(cmd, vv, buckets)
+		}
 	}
 	fmt.Fprintln(cmd.OutOrStdout())
 }
 
-func printQueryResult(cmd *cobra.Command, result *internal.Param, displayNum int, queryParams map[string]map[string]int, k string, v int) {
+func printQueryResult(cmd *cobra.Command, result *internal.Param, displayNum int, buckets int, queryParams map[string]map[string]int, k string, v int) {
 	//cmd.PrintOutln("\tQuery parameter")
 	queryKeys := maps.Keys(queryParams)
 	slices.Sort(queryKeys)
@@ -193,6 +304,9 @@ func printQueryResult(cmd *cobra.Command, result *internal.Param, displayNum int
 		if ks > displayNum {
 			fmt.Fprintf(cmd.OutOrStdout(), "\t\t... and %s more\n", humanize.Comma(int64(ks-displayNum)))
 		}
+		if buckets > 0 {
+			printParamDistribution(cmd, vv, buckets)
+		}
 	}
 
 	if len(result.QueryKeyCombination[k]) > 1 {
@@ -275,7 +389,43 @@ func printQueryResult(cmd *cobra.Command, result *internal.Param, displayNum int
 	fmt.Fprintln(cmd.OutOrStdout())
 }
 
-func printParamStat(cmd *cobra.Command, result *internal.Param, paramType, format string) {
+const histogramWidth = 40
+
+func printParamDistribution(cmd *cobra.Command, values map[string]int, buckets int) {
+	t := internal.InferParamType(values)
+	fmt.Fprintf(cmd.OutOrStdout(), "\t\tType: %s\n", color.YellowString(t.String()))
+	if !t.IsNumeric() {
+		return
+	}
+	d := internal.NewDistribution(values, buckets, t == internal.ParamTypeInteger)
+	if d == nil {
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "\t\tMin: %s, Max: %s, Mean: %s, P50: %s, P90: %s, P95: %s, P99: %s\n",
+		humanize.Ftoa(d.Min), humanize.Ftoa(d.Max), humanize.FtoaWithDigits(d.Mean, 2), humanize.Ftoa(d.P50), humanize.Ftoa(d.P90), humanize.Ftoa(d.P95), humanize.Ftoa(d.P99))
+
+	labels := make([]string, 0, len(d.Histogram))
+	labelWidth, maxCount := 0, 0
+	for i, b := range d.Histogram {
+		closing := ")"
+		if i == len(d.Histogram)-1 {
+			closing = "]"
+			if t == internal.ParamTypeInteger {
+				b.Upper = d.Max
+			}
+		}
+		label := fmt.Sprintf("[%s, %s%s", humanize.FtoaWithDigits(b.Lower, 2), humanize.FtoaWithDigits(b.Upper, 2), closing)
+		labels = append(labels, label)
+		labelWidth = max(labelWidth, len(label))
+		maxCount = max(maxCount, b.Count)
+	}
+	for i, b := range d.Histogram {
+		bar := strings.Repeat("█", b.Count*histogramWidth/maxCount)
+		fmt.Fprintf(cmd.OutOrStdout(), "\t\t%-*s %s %s\n", labelWidth, labels[i], color.CyanString(bar), humanize.Comma(int64(b.Count)))
+	}
+}
+
+func printParamStat(cmd *cobra.Command, result *internal.Param, paramType, format string, dist bool) {
 	rows := make([]table.Row, 0)
 	for _, k := range result.Endpoints {
 		v := result.Count[k]
@@ -294,7 +444,7 @@ func printParamStat(cmd *cobra.Command, result *internal.Param, paramType, forma
 					paramName = fmt.Sprintf("Path param(%d)", i+1)
 				}
 				g, _ := gini.Gini(maps.Values(vv))
-				rows = append(rows, table.Row{
+				row := table.Row{
 					k,
 					"path",
 					paramName,
@@ -302,7 +452,11 @@ func printParamStat(cmd *cobra.Command, result *internal.Param, paramType, forma
 					color.HiBlackString("100.00"),
 					humanize.Comma(int64(len(vv))),
 					printGini(g, false),
-				})
+				}
+				if dist {
+					row = append(row, internal.InferParamType(vv).String())
+				}
+				rows = append(rows, row)
 			}
 		}
 
@@ -312,7 +466,7 @@ func printParamStat(cmd *cobra.Command, result *internal.Param, paramType, forma
 			for _, kk := range queryKeys {
 				vv := queryParams[kk]
 				g, _ := gini.Gini(maps.Values(vv))
-				rows = append(rows, table.Row{
+				row := table.Row{
 					k,
 					"query",
 					fmt.Sprintf("?%s", kk),
@@ -320,7 +474,11 @@ func printParamStat(cmd *cobra.Command, result *internal.Param, paramType, forma
 					fmt.Sprintf("%.2f", float64(result.QueryKey[k][kk])/float64(v)*100),
 					humanize.Comma(int64(len(vv))),
 					printGini(g, false),
-				})
+				}
+				if dist {
+					row = append(row, internal.InferParamType(vv).String())
+				}
+				rows = append(rows, row)
 			}
 		}
 	}
@@ -328,6 +486,9 @@ func printParamStat(cmd *cobra.Command, result *internal.Param, paramType, forma
 	t := table.NewWriter()
 	t.SetOutputMirror(cmd.OutOrStdout())
 	header := table.Row{"Endpoint", "Type", "Parameter", "Count", "Count(%)", "Cardinality", "Gini"}
+	if dist {
+		header = append(header, "Value type")
+	}
 	t.AppendHeader(header)
 
 	aligns := []table.ColumnConfig{{Number: 4, Align: text.AlignRight}, {Number: 5, Align: text.AlignRight}, {Number: 6, Align: text.AlignRight}, {Number: 7, Align: text.AlignRight}}
@@ -347,6 +508,140 @@ func printParamStat(cmd *cobra.Command, result *internal.Param, paramType, forma
 	}
 }
 
+// ParamReport is the machine-readable output of the param command
+type ParamReport struct {
+	Endpoints []ParamEndpointReport `json:"endpoints" yaml:"endpoints"`
+}
+
+type ParamEndpointReport struct {
+	Method                 string                  `json:"method" yaml:"method"`
+	Uri                    string                  `json:"uri" yaml:"uri"` // URI or the matched pattern of matching_groups
+	Count                  int                     `json:"count" yaml:"count"`
+	PathParams             []ParamValueReport      `json:"path_params,omitempty" yaml:"path_params,omitempty"`
+	QueryParams            []ParamValueReport      `json:"query_params,omitempty" yaml:"query_params,omitempty"`
+	QueryKeyCombinations   *ParamCombinationReport `json:"query_key_combinations,omitempty" yaml:"query_key_combinations,omitempty"`
+	QueryValueCombinations *ParamCombinationReport `json:"query_value_combinations,omitempty" yaml:"query_value_combinations,omitempty"`
+}
+
+type ParamValueReport struct {
+	Name         string                 `json:"name" yaml:"name"`                             // name of the capture group (empty if unnamed) or the query key
+	Position     int                    `json:"position,omitempty" yaml:"position,omitempty"` // 1-based position of the path parameter
+	Count        int                    `json:"count" yaml:"count"`                           // number of requests with the parameter
+	Ratio        float64                `json:"ratio" yaml:"ratio"`                           // Count / the count of the endpoint
+	Cardinality  int                    `json:"cardinality" yaml:"cardinality"`
+	Gini         float64                `json:"gini" yaml:"gini"`
+	Top          []ParamTopValue        `json:"top" yaml:"top"`
+	Type         internal.ParamType     `json:"type" yaml:"type"`                                     // one of integer, float, boolean, uuid, date, enum and text
+	Distribution *internal.Distribution `json:"distribution,omitempty" yaml:"distribution,omitempty"` // only for integer and float
+}
+
+type ParamCombinationReport struct {
+	Cardinality int             `json:"cardinality" yaml:"cardinality"`
+	Gini        float64         `json:"gini" yaml:"gini"`
+	Top         []ParamTopValue `json:"top" yaml:"top"` // empty value means requests without query
+}
+
+type ParamTopValue struct {
+	Value           string  `json:"value" yaml:"value"`
+	Count           int     `json:"count" yaml:"count"`
+	CumulativeRatio float64 `json:"cumulative_ratio" yaml:"cumulative_ratio"`
+}
+
+func printParamReport(cmd *cobra.Command, result *internal.Param, paramType string, displayNum int, buckets int, format string) error {
+	report := ParamReport{Endpoints: make([]ParamEndpointReport, 0, len(result.Endpoints))}
+	for _, k := range result.Endpoints {
+		v := result.Count[k]
+		method, uri, _ := strings.Cut(k, " ")
+		e := ParamEndpointReport{Method: method, Uri: uri, Count: v}
+
+		if paramType == "path" || paramType == "all" {
+			for i, vv := range result.Path[k] {
+				e.PathParams = append(e.PathParams, newParamValueReport(result.PathName[k][i], i+1, v, v, vv, displayNum, buckets))
+			}
+		}
+		if _, hasQuery := result.QueryValue[k]; hasQuery && (paramType == "query" || paramType == "all") {
+			queryKeys := maps.Keys(result.QueryValue[k])
+			slices.Sort(queryKeys)
+			for _, kk := range queryKeys {
+				e.QueryParams = append(e.QueryParams, newParamValueReport(kk, 0, result.QueryKey[k][kk], v, result.QueryValue[k][kk], displayNum, buckets))
+			}
+			e.QueryKeyCombinations = newParamCombinationReport(result.QueryKeyCombination[k], v, displayNum)
+			e.QueryValueCombinations = newParamCombinationReport(result.QueryValueCombination[k], v, displayNum)
+		}
+		report.Endpoints = append(report.Endpoints, e)
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	enc := yaml.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent(2)
+	return enc.Encode(report)
+}
+
+func newParamValueReport(name string, position, count, endpointCount int, values map[string]int, displayNum int, buckets int) ParamValueReport {
+	g, _ := gini.Gini(maps.Values(values))
+	t := internal.InferParamType(values)
+	var d *internal.Distribution
+	if t.IsNumeric() {
+		d = internal.NewDistribution(values, buckets, t == internal.ParamTypeInteger)
+	}
+	return ParamValueReport{
+		Name:         name,
+		Position:     position,
+		Count:        count,
+		Ratio:        float64(count) / float64(endpointCount),
+		Cardinality:  len(values),
+		Gini:         g,
+		Top:          topValues(values, count, displayNum),
+		Type:         t,
+		Distribution: d,
+	}
+}
+
+func newParamCombinationReport(combinations map[string]int, endpointCount int, displayNum int) *ParamCombinationReport {
+	values := maps.Clone(combinations)
+	var sum int
+	for _, c := range combinations {
+		sum += c
+	}
+	if endpointCount-sum > 0 {
+		values[""] = endpointCount - sum
+	}
+	g, _ := gini.Gini(maps.Values(combinations))
+	return &ParamCombinationReport{
+		Cardinality: len(values),
+		Gini:        g,
+		Top:         topValues(values, endpointCount, displayNum),
+	}
+}
+
+// topValues returns the most frequent values in descending order of their counts
+func topValues(values map[string]int, total int, displayNum int) []ParamTopValue {
+	ss := make([]kv, 0, len(values))
+	for k, v := range values {
+		ss = append(ss, kv{k, v})
+	}
+	slices.SortFunc(ss, func(a, b kv) int {
+		if a.Value != b.Value {
+			return cmp.Compare(b.Value, a.Value)
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	if len(ss) > displayNum {
+		ss = ss[:displayNum]
+	}
+	top := make([]ParamTopValue, 0, len(ss))
+	var p int
+	for _, s := range ss {
+		p += s.Value
+		top = append(top, ParamTopValue{Value: s.Key, Count: s.Value, CumulativeRatio: float64(p) / float64(total)})
+	}
+	return top
+}
+
 func emphasisInt(num int) string {
 	return color.New(color.Bold).Sprint(humanize.Comma(int64(num)))
 }
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/haijima/stool/internal"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// printApproxParamResult prints the top values of the parameters tracked with --approx.
+// Each count is printed with its maximum overestimation, and each cardinality with its standard error.
+func printApproxParamResult(cmd *cobra.Command, result *internal.ApproxParam, paramType string, displayNum int) {
+	for _, k := range result.Endpoints {
+		v := result.Count[k]
+		pathParams, hasPathParam := result.Path[k]
+		queryParams, hasQuery := result.QueryValue[k]
+		if !(hasPathParam && (paramType == "path" || paramType == "all")) && !(hasQuery && (paramType == "query" || paramType == "all")) {
+			continue
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%s (Count: %s)\n", color.New(color.FgHiBlue, color.Underline).Sprint(k), emphasisInt(v))
+
+		if hasPathParam && (paramType == "path" || paramType == "all") {
+			for i, vv := range pathParams {
+				var paramName string
+				if result.PathName[k][i] != "" {
+					paramName = ":" + color.CyanString(result.PathName[k][i])
+				} else {
+					paramName = color.CyanString(fmt.Sprintf("%s path parameter", humanize.Ordinal(i+1)))
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "\t%s (Cardinality: %s)\n", paramName, approxCardinality(vv))
+				printApproxValues(cmd, vv, displayNum, v, greenString)
+			}
+			fmt.Fprintln(cmd.OutOrStdout())
+		}
+		if hasQuery && (paramType == "query" || paramType == "all") {
+			queryKeys := maps.Keys(queryParams)
+			slices.Sort(queryKeys)
+			for _, kk := range queryKeys {
+				vv := queryParams[kk]
+				fmt.Fprintf(cmd.OutOrStdout(), "\t?%s (Count: %s, Rate: %s, Cardinality: %s)\n", color.MagentaString(kk), emphasisInt(result.QueryKey[k][kk]), emphasisPercentage(result.QueryKey[k][kk], v), approxCardinality(vv))
+				printApproxValues(cmd, vv, displayNum, result.QueryKey[k][kk], greenString)
+			}
+			if kc := result.QueryKeyCombination[k]; kc.Cardinality.Count() > 1 {
+				fmt.Fprintf(cmd.OutOrStdout(), "\n\tQuery key combination (Cardinality: %s)\n", approxCardinality(kc))
+				printApproxValues(cmd, kc, displayNum, v, func(s string) string {
+					return "?" + color.MagentaString("%s", s)
+				})
+				vc := result.QueryValueCombination[k]
+				fmt.Fprintf(cmd.OutOrStdout(), "\n\tQuery key value combination (Cardinality: %s)\n", approxCardinality(vc))
+				printApproxValues(cmd, vc, displayNum, v, func(s string) string { return "?" + s })
+			}
+			fmt.Fprintln(cmd.OutOrStdout())
+		}
+	}
+}
+
+func printApproxValues(cmd *cobra.Command, values *internal.ApproxValues, displayNum int, total int, colorize func(string) string) {
+	var p int
+	for _, c := range values.TopK.Top(displayNum) {
+		p += c.Count
+		fmt.Fprintf(cmd.OutOrStdout(), "\t\t%s: %s (±%s, Cum: %s)\n", colorize(c.Item), emphasisInt(c.Count), humanize.Comma(int64(c.Error)), emphasisPercentage(min(p, total), total)) // counts may be overestimated
+	}
+	if e := values.TopK.MaxError(); e > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "\t\tValues not shown occur at most %s times each\n", humanize.Comma(int64(e)))
+	}
+}
+
+func greenString(s string) string {
+	return color.GreenString("%s", s)
+}
+
+func approxCardinality(values *internal.ApproxValues) string {
+	return fmt.Sprintf("~%s ±%.2f%%", emphasisInt(values.Cardinality.Count()), values.Cardinality.StdError()*100)
+}
+
+func printApproxParamStat(cmd *cobra.Command, result *internal.ApproxParam, paramType, format string) {
+	rows := make([]table.Row, 0)
+	var stdErr float64
+	for _, k := range result.Endpoints {
+		v := result.Count[k]
+		if paramType == "path" || paramType == "all" {
+			for i, vv := range result.Path[k] {
+				var paramName string
+				if result.PathName[k][i] != "" {
+					paramName = ":" + result.PathName[k][i]
+				} else {
+					paramName = fmt.Sprintf("Path param(%d)", i+1)
+				}
+				stdErr = vv.Cardinality.StdError()
+				rows = append(rows, table.Row{k, "path", paramName, humanize.Comma(int64(v)), color.HiBlackString("100.00"),
+					humanize.Comma(int64(vv.Cardinality.Count())), humanize.Comma(int64(vv.TopK.MaxError()))})
+			}
+		}
+		if paramType == "query" || paramType == "all" {
+			queryKeys := maps.Keys(result.QueryValue[k])
+			slices.Sort(queryKeys)
+			for _, kk := range queryKeys {
+				vv := result.QueryValue[k][kk]
+				stdErr = vv.Cardinality.StdError()
+				rows = append(rows, table.Row{k, "query", fmt.Sprintf("?%s", kk), humanize.Comma(int64(result.QueryKey[k][kk])),
+					fmt.Sprintf("%.2f", float64(result.QueryKey[k][kk])/float64(v)*100),
+					humanize.Comma(int64(vv.Cardinality.Count())), humanize.Comma(int64(vv.TopK.MaxError()))})
+			}
+		}
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+	t.AppendHeader(table.Row{"Endpoint", "Type", "Parameter", "Count", "Count(%)", "Cardinality(approx)", "Max count error"})
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 4, Align: text.AlignRight}, {Number: 5, Align: text.AlignRight}, {Number: 6, Align: text.AlignRight}, {Number: 7, Align: text.AlignRight}})
+	t.AppendRows(rows)
+	if format == "csv" {
+		t.RenderCSV()
+	} else if format == "tsv" {
+		t.RenderTSV()
+	} else if format == "table" {
+		t.Render()
+	} else if format == "md" {
+		t.RenderMarkdown()
+	} else {
+		cmd.PrintErrf("invalid format: %s\n", format)
+	}
+	if len(rows) > 0 && (format == "table" || format == "md") {
+		fmt.Fprintf(cmd.OutOrStdout(), "Cardinality(approx) has a standard error of %.2f%%.\n", stdErr*100)
+	}
+}
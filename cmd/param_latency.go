@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/haijima/stool/internal"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// printParamLatencyResult prints the values of the parameters that consume the most response time.
+// Each parameter is printed with the ratio of the variance of response times explained by its value.
+func printParamLatencyResult(cmd *cobra.Command, result *internal.ParamLatency, paramType string, displayNum int) {
+	for _, k := range result.Endpoints {
+		pathParams, hasPathParam := result.Path[k]
+		queryParams, hasQuery := result.Query[k]
+		hasPathParam = hasPathParam && (paramType == "path" || paramType == "all")
+		hasQuery = hasQuery && (paramType == "query" || paramType == "all")
+		if !hasPathParam && !hasQuery {
+			continue
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%s (Count: %s, Total: %s)\n", color.New(color.FgHiBlue, color.Underline).Sprint(k), emphasisInt(result.Count[k]), emphasisSeconds(result.Total[k]))
+
+		if hasPathParam {
+			for i, vv := range pathParams {
+				var paramName string
+				if result.PathName[k][i] != "" {
+					paramName = ":" + color.CyanString(result.PathName[k][i])
+				} else {
+					paramName = color.CyanString(fmt.Sprintf("%s path parameter", humanize.Ordinal(i+1)))
+				}
+				printLatencyValues(cmd, paramName, vv, displayNum)
+			}
+		}
+		if hasQuery {
+			queryKeys := maps.Keys(queryParams)
+			slices.Sort(queryKeys)
+			for _, kk := range queryKeys {
+				printLatencyValues(cmd, "?"+color.MagentaString(kk), queryParams[kk], displayNum)
+			}
+		}
+		fmt.Fprintln(cmd.OutOrStdout())
+	}
+}
+
+func printLatencyValues(cmd *cobra.Command, paramName string, values map[string]*internal.LatencySamples, displayNum int) {
+	stats := internal.NewLatencyStats(values)
+	var total float64
+	for _, s := range stats {
+		total += s.Total
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "\t%s (Cardinality: %s, Explained variance: %s)\n", paramName, emphasisInt(len(stats)), printExplainedVariance(internal.ExplainedVariance(values), true))
+
+	var cum float64
+	for i, s := range stats {
+		if i >= displayNum {
+			fmt.Fprintf(cmd.OutOrStdout(), "\t\t... and %s more\n", humanize.Comma(int64(len(stats)-displayNum)))
+			break
+		}
+		cum += s.Total
+		fmt.Fprintf(cmd.OutOrStdout(), "\t\t%s: Total %s (Count: %s, Mean: %s, P95: %s, Cum: %s)\n",
+			color.GreenString(s.Value), emphasisSeconds(s.Total), humanize.Comma(int64(s.Count)), formatSeconds(s.Mean), formatSeconds(s.P95), color.New(color.Bold).Sprintf("%.2f%%", cum/total*100))
+	}
+}
+
+func printParamLatencyStat(cmd *cobra.Command, result *internal.ParamLatency, paramType, format string) {
+	rows := make([]table.Row, 0)
+	appendRow := func(k, typ, paramName string, values map[string]*internal.LatencySamples) {
+		stats := internal.NewLatencyStats(values)
+		var count int
+		var total float64
+		for _, s := range stats {
+			count += s.Count
+			total += s.Total
+		}
+		rows = append(rows, table.Row{
+			k,
+			typ,
+			paramName,
+			humanize.Comma(int64(count)),
+			humanize.Comma(int64(len(stats))),
+			fmt.Sprintf("%.3f", total),
+			stats[0].Value,
+			fmt.Sprintf("%.2f", stats[0].Total/total*100),
+			printExplainedVariance(internal.ExplainedVariance(values), false),
+		})
+	}
+	for _, k := range result.Endpoints {
+		if paramType == "path" || paramType == "all" {
+			for i, vv := range result.Path[k] {
+				var paramName string
+				if result.PathName[k][i] != "" {
+					paramName = ":" + result.PathName[k][i]
+				} else {
+					paramName = fmt.Sprintf("Path param(%d)", i+1)
+				}
+				appendRow(k, "path", paramName, vv)
+			}
+		}
+		if paramType == "query" || paramType == "all" {
+			queryKeys := maps.Keys(result.Query[k])
+			slices.Sort(queryKeys)
+			for _, kk := range queryKeys {
+				appendRow(k, "query", fmt.Sprintf("?%s", kk), result.Query[k][kk])
+			}
+		}
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+	t.AppendHeader(table.Row{"Endpoint", "Type", "Parameter", "Count", "Cardinality", "Total(s)", "Top value", "Top value(%)", "Explained variance"})
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 4, Align: text.AlignRight}, {Number: 5, Align: text.AlignRight}, {Number: 6, Align: text.AlignRight}, {Number: 8, Align: text.AlignRight}, {Number: 9, Align: text.AlignRight}})
+	t.AppendRows(rows)
+	if format == "csv" {
+		t.RenderCSV()
+	} else if format == "tsv" {
+		t.RenderTSV()
+	} else if format == "table" {
+		t.Render()
+	} else if format == "md" {
+		t.RenderMarkdown()
+	} else {
+		cmd.PrintErrf("invalid format: %s\n", format)
+	}
+}
+
+func formatSeconds(sec float64) string {
+	return fmt.Sprintf("%.3fs", sec)
+}
+
+func emphasisSeconds(sec float64) string {
+	return color.New(color.Bold).Sprint(formatSeconds(sec))
+}
+
+// printExplainedVariance colors the explained variance like printGini. The higher, the more the value matters.
+func printExplainedVariance(ev float64, bold bool) string {
+	c := color.New()
+	if ev < 0.25 {
+		// Noop
+	} else if ev < 0.5 {
+		c.Add(color.FgYellow)
+	} else {
+		c.Add(color.FgRed)
+	}
+	if bold {
+		c.Add(color.Bold)
+	}
+	return c.Sprint(fmt.Sprintf("%.3f", ev))
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/haijima/stool/internal"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// printPaginationResult prints how deep users paginate, the page sizes and the walks of consecutive pages of each endpoint
+func printPaginationResult(cmd *cobra.Command, result *internal.Pagination, displayNum int) {
+	for _, k := range result.Endpoints {
+		ep := result.Paging[k]
+		fmt.Fprintf(cmd.OutOrStdout(), "%s (Count: %s, Paging: %s)\n", color.New(color.FgHiBlue, color.Underline).Sprint(k), emphasisInt(result.Count[k]), emphasisInt(ep.Requests))
+
+		positionKeys := maps.Keys(ep.Position)
+		slices.Sort(positionKeys)
+		for _, pk := range positionKeys {
+			values := ep.Position[pk]
+			var count int
+			for _, c := range values {
+				count += c
+			}
+			if d := internal.NewDistribution(numericCounts(values), 1, true); d != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "\t?%s (Count: %s, Max: %s, P50: %s, P90: %s, P99: %s)\n", color.MagentaString(pk), emphasisInt(count),
+					emphasisFloat(d.Max), humanize.Ftoa(d.P50), humanize.Ftoa(d.P90), humanize.Ftoa(d.P99))
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "\t?%s (Count: %s, Cardinality: %s)\n", color.MagentaString(pk), emphasisInt(count), emphasisInt(len(values)))
+			}
+		}
+
+		if len(ep.PageSize) > 0 {
+			var count int
+			for _, c := range ep.PageSize {
+				count += c
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "\tPage size (Count: %s, Cardinality: %s)\n", emphasisInt(count), emphasisInt(len(ep.PageSize)))
+			for _, t := range topValues(ep.PageSize, count, displayNum) {
+				fmt.Fprintf(cmd.OutOrStdout(), "\t\t%s: %s (Cum: %s)\n", color.GreenString(t.Value), emphasisInt(t.Count), color.New(color.Bold).Sprintf("%.2f%%", t.CumulativeRatio*100))
+			}
+			if len(ep.PageSize) > displayNum {
+				fmt.Fprintf(cmd.OutOrStdout(), "\t\t... and %s more\n", humanize.Comma(int64(len(ep.PageSize)-displayNum)))
+			}
+		}
+
+		if len(ep.Walks) > 0 {
+			var pages int
+			for _, w := range ep.Walks {
+				pages += w
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "\tConsecutive pages (Walks: %s, Multi-page walks: %s, Mean pages: %s, Max pages: %s)\n", emphasisInt(len(ep.Walks)),
+				emphasisPercentage(ep.MultiPageWalks(), len(ep.Walks)), humanize.FtoaWithDigits(float64(pages)/float64(len(ep.Walks)), 2), emphasisInt(ep.Walks[len(ep.Walks)-1]))
+		}
+		fmt.Fprintln(cmd.OutOrStdout())
+	}
+}
+
+func printPaginationStat(cmd *cobra.Command, result *internal.Pagination, format string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+	t.AppendHeader(table.Row{"Endpoint", "Position", "Count", "Max", "P50", "P90", "P99", "Page sizes", "Walks", "Multi-page walks(%)", "Max pages"})
+	for _, k := range result.Endpoints {
+		ep := result.Paging[k]
+		positionKeys := maps.Keys(ep.Position)
+		slices.Sort(positionKeys)
+
+		row := table.Row{k, strings.Join(positionKeys, ",")}
+		if d := internal.NewDistribution(numericCounts(mergeCounts(ep.Position)), 1, true); d != nil {
+			row = append(row, humanize.Comma(int64(ep.Requests)), humanize.Ftoa(d.Max), humanize.Ftoa(d.P50), humanize.Ftoa(d.P90), humanize.Ftoa(d.P99))
+		} else {
+			row = append(row, humanize.Comma(int64(ep.Requests)), "-", "-", "-", "-")
+		}
+
+		sizes := make([]string, 0)
+		for _, tv := range topValues(ep.PageSize, 0, 3) {
+			sizes = append(sizes, tv.Value)
+		}
+		row = append(row, strings.Join(sizes, ","))
+
+		if len(ep.Walks) > 0 {
+			row = append(row, humanize.Comma(int64(len(ep.Walks))), fmt.Sprintf("%.2f", float64(ep.MultiPageWalks())/float64(len(ep.Walks))*100), humanize.Comma(int64(ep.Walks[len(ep.Walks)-1])))
+		} else {
+			row = append(row, "0", "-", "-")
+		}
+		t.AppendRow(row)
+	}
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 3, Align: text.AlignRight}, {Number: 4, Align: text.AlignRight}, {Number: 5, Align: text.AlignRight}, {Number: 6, Align: text.AlignRight}, {Number: 7, Align: text.AlignRight}, {Number: 9, Align: text.AlignRight}, {Number: 10, Align: text.AlignRight}, {Number: 11, Align: text.AlignRight}})
+
+	if format == "csv" {
+		t.RenderCSV()
+	} else if format == "tsv" {
+		t.RenderTSV()
+	} else if format == "table" {
+		t.Render()
+	} else if format == "md" {
+		t.RenderMarkdown()
+	} else {
+		cmd.PrintErrf("invalid format: %s\n", format)
+	}
+}
+
+// mergeCounts merges the value counts of the position keys. An endpoint usually has only one of them.
+func mergeCounts(counts map[string]map[string]int) map[string]int {
+	merged := make(map[string]int)
+	for _, values := range counts {
+		for v, c := range values {
+			merged[v] += c
+		}
+	}
+	return merged
+}
+
+// numericCounts returns the counts of the finite numeric values, so that a few malformed positions like "nan" or "abc"
+// do not spoil the distribution of the others
+func numericCounts(counts map[string]int) map[string]int {
+	numeric := make(map[string]int)
+	for v, c := range counts {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+			numeric[v] = c
+		}
+	}
+	return numeric
+}
+
+func emphasisFloat(f float64) string {
+	return color.New(color.Bold).Sprint(humanize.Ftoa(f))
+}
@@ -76,3 +76,210 @@ func TestNewParamCmdExecute(t *testing.T) {
 
 	assert.NoError(t, cmd.Execute())
 }
+
+func TestNewParamCmd_RunE_format_json(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewParamCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "json")
+	v.Set("dist", true)
+	v.Set("matching_groups", []string{"^/api/users/(?P<id>[0-9]+)$"})
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET /api/users/1?page=1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"endpoints": [{
+		"method": "GET",
+		"uri": "^/api/users/(?P<id>[0-9]+)$",
+		"count": 2,
+		"path_params": [{"name": "id", "position": 1, "count": 2, "ratio": 1, "cardinality": 1, "gini": 0, "top": [{"value": "1", "count": 2, "cumulative_ratio": 1}],
+			"type": "integer", "distribution": {"min": 1, "max": 1, "mean": 1, "p50": 1, "p90": 1, "p95": 1, "p99": 1, "histogram": [{"lower": 1, "upper": 2, "count": 2}]}}],
+		"query_params": [{"name": "page", "count": 1, "ratio": 0.5, "cardinality": 1, "gini": 0, "top": [{"value": "1", "count": 1, "cumulative_ratio": 1}],
+			"type": "integer", "distribution": {"min": 1, "max": 1, "mean": 1, "p50": 1, "p90": 1, "p95": 1, "p99": 1, "histogram": [{"lower": 1, "upper": 2, "count": 1}]}}],
+		"query_key_combinations": {"cardinality": 2, "gini": 0, "top": [{"value": "", "count": 1, "cumulative_ratio": 0.5}, {"value": "page", "count": 1, "cumulative_ratio": 1}]},
+		"query_value_combinations": {"cardinality": 2, "gini": 0, "top": [{"value": "", "count": 1, "cumulative_ratio": 0.5}, {"value": "page=1", "count": 1, "cumulative_ratio": 1}]}
+	}]}`, stdout.String())
+}
+
+func TestNewParamCmd_RunE_dist(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewParamCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("dist", true)
+	v.Set("buckets", 2)
+	v.Set("no_color", true)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET /api/users?offset=0 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/users?offset=20 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:03 +0900\treq:GET /api/users?offset=40 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "\t\tType: integer\n")
+	assert.Contains(t, stdout.String(), "\t\tMin: 0, Max: 40, Mean: 20, P50: 20, P90: 40, P95: 40, P99: 40\n")
+	assert.Contains(t, stdout.String(), "[0, 21)")
+	assert.Contains(t, stdout.String(), "[21, 40]")
+}
+
+func TestNewParamCmd_RunE_approx(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewParamCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("approx", true)
+	v.Set("capacity", 1)
+	v.Set("matching_groups", []string{"^/api/users/(?P<id>[^/]+)$"})
+	v.Set("no_color", true)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET /api/users/1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:03 +0900\treq:GET /api/users/2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "GET ^/api/users/(?P<id>[^/]+)$ (Count: 3)\n\t:id (Cardinality: ~2 ±0.81%)\n\t\t2: 3 (±2, Cum: 100.00%)\n\t\tValues not shown occur at most 3 times each\n\n", stdout.String())
+}
+
+func TestNewParamCmd_RunE_approx_json(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewParamCmd(p, v, fs)
+
+	v.Set("approx", true)
+	v.Set("format", "json")
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.EqualError(t, err, "approx flag cannot be used with dist flag nor json/yaml format")
+}
+
+func TestNewParamCmd_RunE_latency(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewParamCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("latency", true)
+	v.Set("type", "path")
+	v.Set("matching_groups", []string{"^/api/users/(?P<id>[^/]+)$"})
+	v.Set("no_color", true)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET /api/users/1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\treqtime:0.100\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\treqtime:0.300\ntime:01/Jan/2023:12:00:03 +0900\treq:GET /api/users/2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\treqtime:0.600\ntime:01/Jan/2023:12:00:04 +0900\treq:GET /api/users/3 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\treqtime:-\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "GET ^/api/users/(?P<id>[^/]+)$ (Count: 3, Total: 1.000s)\n"+
+		"\t:id (Cardinality: 2, Explained variance: 0.842)\n"+
+		"\t\t2: Total 0.600s (Count: 1, Mean: 0.600s, P95: 0.600s, Cum: 60.00%)\n"+
+		"\t\t1: Total 0.400s (Count: 2, Mean: 0.200s, P95: 0.300s, Cum: 100.00%)\n\n", stdout.String())
+}
+
+func TestNewParamCmd_RunE_latency_NoReqTime(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewParamCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("latency", true)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET /api/users/1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n"), 0777)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.EqualError(t, err, "\"reqtime\" field is not found in the log. Use --log_labels reqtime=<label> to specify the label of the response time")
+}
+
+func TestNewParamCmd_RunE_paging(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewParamCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("paging", true)
+	v.Set("no_color", true)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET /api/users?offset=0&limit=20 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/users?offset=20&limit=20 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:03 +0900\treq:GET /api/users?offset=40&limit=20 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "GET /api/users (Count: 3, Paging: 3)\n"+
+		"\t?offset (Count: 3, Max: 40, P50: 20, P90: 40, P99: 40)\n"+
+		"\tPage size (Count: 3, Cardinality: 1)\n"+
+		"\t\t20: 3 (Cum: 100.00%)\n"+
+		"\tConsecutive pages (Walks: 1, Multi-page walks: 100.00%, Mean pages: 3, Max pages: 3)\n\n", stdout.String())
+}
+
+func TestNewParamCmd_RunE_paging_NonNumeric(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewParamCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("paging", true)
+	v.Set("no_color", true)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET /api/users?page=1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/users?page=nan HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:03 +0900\treq:GET /api/users?page=abc HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "GET /api/users (Count: 3, Paging: 3)\n"+
+		"\t?page (Count: 3, Max: 1, P50: 1, P90: 1, P99: 1)\n"+
+		"\tConsecutive pages (Walks: 3, Multi-page walks: 0.00%, Mean pages: 1, Max pages: 1)\n\n", stdout.String())
+}
+
+func TestNewParamCmd_RunE_trend(t *testing.T) {
+	p := internal.NewParamProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewParamCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("trend", "?offset")
+	v.Set("interval", 1)
+	v.Set("format", "csv")
+	v.Set("num", 2)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET /api/users?offset=0 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/users?offset=0 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/users?offset=20 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:03 +0900\treq:GET /api/users?offset=40 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Method,Uri,Parameter,Value,Count,Share(%),0,1,2\nGET,/api/users,?offset,0,2,50.00,1,1,0\nGET,/api/users,?offset,20,1,25.00,0,1,0\n", stdout.String())
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/haijima/stool/internal"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+// printParamTrend prints the counts at each interval of the top values of the parameter in the same way as trend
+func printParamTrend(cmd *cobra.Command, result *internal.ParamTrend, param string, displayNum int, format string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+
+	header := table.Row{"Method", "Uri", "Parameter", "Value", "Count", "Share(%)"}
+	for i := 0; i < result.Step; i++ {
+		header = append(header, strconv.Itoa(i*result.Interval))
+	}
+	t.AppendHeader(header)
+
+	aligns := make([]table.ColumnConfig, 0, len(header)-4)
+	for i := 5; i <= len(header); i++ {
+		aligns = append(aligns, table.ColumnConfig{Number: i, Align: text.AlignRight})
+	}
+	t.SetColumnConfigs(aligns)
+
+	humanized := format == "table" || format == "md"
+	for _, endpoint := range result.Endpoints {
+		method, uri, _ := strings.Cut(endpoint, " ")
+		for _, value := range result.TopValues(endpoint, displayNum) {
+			sum := result.Sum[endpoint][value]
+			row := table.Row{method, uri, param, value}
+			if humanized {
+				row = append(row, humanize.Comma(int64(sum)))
+			} else {
+				row = append(row, strconv.Itoa(sum))
+			}
+			row = append(row, fmt.Sprintf("%.2f", float64(sum)/float64(result.Total[endpoint])*100))
+			counts := result.Counts(endpoint, value)
+			for i := range counts {
+				row = append(row, trendCell(counts, i, humanized))
+			}
+			t.AppendRow(row)
+		}
+	}
+
+	switch format {
+	case "table":
+		t.Render()
+	case "md":
+		t.RenderMarkdown()
+	case "csv":
+		t.RenderCSV()
+	case "tsv":
+		t.RenderTSV()
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/haijima/cobrax"
+	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/log"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NewReplayCmd returns the replay command
+func NewReplayCmd(p *internal.Replayer, v *viper.Viper, fs afero.Fs) *cobra.Command {
+	replayCmd := &cobra.Command{}
+	replayCmd.Use = "replay"
+	replayCmd.Short = "Replay the logged requests against a server"
+	replayCmd.Example = "  stool replay --file path/to/access.log --base_url http://localhost:8080 --speed 2"
+	replayCmd.Args = cobra.NoArgs
+	replayCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runReplay(cmd, v, fs, p)
+	}
+
+	replayCmd.Flags().String("base_url", "http://localhost:8080", "The base URL of the server to replay the requests against")
+	replayCmd.Flags().StringSlice("method", []string{"GET"}, "Comma-separated list of methods to replay")
+	replayCmd.Flags().Float64("speed", 1, "The speed multiplier of the original timing. 0 means replaying without waiting")
+	replayCmd.Flags().IntP("concurrency", "c", 10, "The number of workers replaying the users, which is the maximum number of requests in flight")
+	replayCmd.Flags().Duration("timeout", 10*time.Second, "The timeout of each request")
+	replayCmd.Flags().String("format", "table", "The output format {table|md|csv|tsv}")
+
+	return replayCmd
+}
+
+func runReplay(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.Replayer) error {
+	matchingGroups := v.GetStringSlice("matching_groups")
+	timeFormat := v.GetString("time_format")
+	labels := v.GetStringMapString("log_labels")
+	filter := v.GetString("filter")
+	format := v.GetString("format")
+	speed := v.GetFloat64("speed")
+	concurrency := v.GetInt("concurrency")
+	methods := v.GetStringSlice("method")
+	for i, m := range methods {
+		methods[i] = strings.ToUpper(m)
+	}
+
+	if speed < 0 {
+		return fmt.Errorf("speed flag should not be negative. but: %g", speed)
+	}
+	if concurrency <= 0 {
+		return fmt.Errorf("concurrency flag should be positive. but: %d", concurrency)
+	}
+	if format != "table" && format != "md" && format != "csv" && format != "tsv" {
+		return fmt.Errorf("format flag should be 'table', 'md', 'csv' or 'tsv'. but: %s", format)
+	}
+
+	f, err := cobrax.OpenOrStdIn(v.GetString("file"), fs, cobrax.WithStdin(cmd.InOrStdin()))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	logReader, err := log.NewLTSVReader(f, log.LTSVReadOpt{
+		MatchingGroups: matchingGroups,
+		TimeFormat:     timeFormat,
+		Labels:         labels,
+		Filter:         filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := p.Replay(logReader, internal.ReplayOption{
+		BaseURL:     v.GetString("base_url"),
+		Methods:     methods,
+		Speed:       speed,
+		Concurrency: concurrency,
+		Client: &http.Client{
+			Timeout:       v.GetDuration("timeout"),
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	printReplay(cmd, result, format)
+	if format == "table" || format == "md" {
+		cmd.Printf("Replayed %s requests of %s users in %s (skipped %s requests)\n",
+			humanize.Comma(int64(sumCounts(result.Count))), humanize.Comma(int64(result.Users)), result.Elapsed.Round(time.Millisecond), humanize.Comma(int64(result.Skipped)))
+	}
+	return nil
+}
+
+func printReplay(cmd *cobra.Command, result *internal.Replay, format string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+	t.AppendHeader(table.Row{"Endpoint", "Requests", "Success", "Success(%)", "Status match(%)", "Errors", "Latency P50", "Latency P95", "Latency max"})
+	for _, k := range result.Endpoints {
+		row := table.Row{k, humanize.Comma(int64(result.Count[k])), humanize.Comma(int64(result.Success[k])),
+			fmt.Sprintf("%.2f", float64(result.Success[k])/float64(result.Count[k])*100),
+			fmt.Sprintf("%.2f", float64(result.StatusMatch[k])/float64(result.Count[k])*100),
+			humanize.Comma(int64(result.Errors[k]))}
+		if latencies := result.Latencies[k]; len(latencies) > 0 {
+			row = append(row, roundLatency(internal.DurationQuantile(latencies, 0.5)), roundLatency(internal.DurationQuantile(latencies, 0.95)), roundLatency(latencies[len(latencies)-1]))
+		} else {
+			row = append(row, "-", "-", "-")
+		}
+		t.AppendRow(row)
+	}
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 2, Align: text.AlignRight}, {Number: 3, Align: text.AlignRight}, {Number: 4, Align: text.AlignRight}, {Number: 5, Align: text.AlignRight}, {Number: 6, Align: text.AlignRight}, {Number: 7, Align: text.AlignRight}, {Number: 8, Align: text.AlignRight}, {Number: 9, Align: text.AlignRight}})
+
+	if format == "csv" {
+		t.RenderCSV()
+	} else if format == "tsv" {
+		t.RenderTSV()
+	} else if format == "table" {
+		t.Render()
+	} else if format == "md" {
+		t.RenderMarkdown()
+	}
+}
+
+// roundLatency rounds the latency to be readable. Latencies less than 1ms are rounded to microseconds.
+func roundLatency(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(100 * time.Microsecond)
+}
+
+func sumCounts(counts map[string]int) int {
+	sum := 0
+	for _, c := range counts {
+		sum += c
+	}
+	return sum
+}
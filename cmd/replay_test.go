@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/haijima/stool/internal"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReplayCmd(t *testing.T) {
+	p := internal.NewReplayer()
+	v, fs := createViperAndFs()
+	cmd := NewReplayCmd(p, v, fs)
+
+	assert.Equal(t, "replay", cmd.Name(), "NewReplayCmd() should return command named \"replay\". but: %q", cmd.Name())
+}
+
+func TestNewReplayCmd_RunE(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		} else if r.URL.Path == "/api/login" {
+			http.Redirect(w, r, "/api/missing", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+
+	p := internal.NewReplayer()
+	v, fs := createViperAndFs()
+	cmd := NewReplayCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "csv")
+	v.Set("base_url", ts.URL)
+	v.Set("speed", 0)
+	v.Set("method", []string{"get", "post"})
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:01 +0900\treq:POST /api/items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /api/missing HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:03 +0900\treq:DELETE /api/items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:04 +0900\treq:GET /api/login HTTP/2.0\tstatus:302\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	assert.Equal(t, 5, len(lines))
+	assert.Equal(t, "Endpoint,Requests,Success,Success(%),Status match(%),Errors,Latency P50,Latency P95,Latency max", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "GET /api/items,1,1,100.00,100.00,0,"), lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "POST /api/items,1,1,100.00,100.00,0,"), lines[2])
+	assert.True(t, strings.HasPrefix(lines[3], "GET /api/login,1,1,100.00,100.00,0,"), lines[3]) // the redirect is not followed
+	assert.True(t, strings.HasPrefix(lines[4], "GET /api/missing,1,0,0.00,0.00,0,"), lines[4])
+}
+
+func TestNewReplayCmd_RunE_invalidSpeed(t *testing.T) {
+	p := internal.NewReplayer()
+	v, fs := createViperAndFs()
+	cmd := NewReplayCmd(p, v, fs)
+
+	v.Set("speed", -1)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.EqualError(t, err, "speed flag should not be negative. but: -1")
+}
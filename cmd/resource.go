@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/haijima/cobrax"
+	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/log"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/exp/maps"
+)
+
+// NewResourceCmd returns the resource command
+func NewResourceCmd(p *internal.ResourceProfiler, v *viper.Viper, fs afero.Fs) *cobra.Command {
+	resourceCmd := &cobra.Command{}
+	resourceCmd.Use = "resource"
+	resourceCmd.Short = "Show the reads and writes of each resource shared by endpoints"
+	resourceCmd.Example = "  stool resource --file path/to/access.log -m \"^/api/isu/(?P<jia_isu_uuid>[^/]+)$,^/api/condition/(?P<jia_isu_uuid>[^/]+)$\""
+	resourceCmd.Args = cobra.NoArgs
+	resourceCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runResource(cmd, v, fs, p)
+	}
+
+	resourceCmd.Flags().IntP("num", "n", 5, "The number of resource IDs to show")
+	resourceCmd.Flags().Bool("stat", false, "Show statistics of each resource ID as a table")
+	resourceCmd.Flags().String("format", "table", "The stat output format {table|md|csv|tsv}")
+
+	return resourceCmd
+}
+
+func runResource(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.ResourceProfiler) error {
+	matchingGroups := v.GetStringSlice("matching_groups")
+	timeFormat := v.GetString("time_format")
+	labels := v.GetStringMapString("log_labels")
+	filter := v.GetString("filter")
+	num := v.GetInt("num")
+	format := v.GetString("format")
+
+	if format != "table" && format != "md" && format != "csv" && format != "tsv" {
+		return fmt.Errorf("format flag should be 'table', 'md', 'csv' or 'tsv'. but: %s", format)
+	}
+
+	f, err := cobrax.OpenOrStdIn(v.GetString("file"), fs, cobrax.WithStdin(cmd.InOrStdin()))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	logReader, err := log.NewLTSVReader(f, log.LTSVReadOpt{
+		MatchingGroups: matchingGroups,
+		TimeFormat:     timeFormat,
+		Labels:         labels,
+		Filter:         filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := p.Profile(logReader)
+	if err != nil {
+		return err
+	}
+	if len(result.Names) == 0 {
+		return fmt.Errorf("no request matches a named capture group. Use named capture groups in matching_groups. e.g. \"/users/(?P<user_id>[^/]+)\"")
+	}
+
+	if v.GetBool("stat") {
+		printResourceStat(cmd, result, num, format)
+	} else {
+		printResourceResult(cmd, result, num)
+	}
+	return nil
+}
+
+func printResourceResult(cmd *cobra.Command, result *internal.Resource, displayNum int) {
+	for _, name := range result.Names {
+		ids := result.SortedIDs(name)
+		var reads, writes int
+		for _, id := range ids {
+			reads += id.Reads
+			writes += id.Writes
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s (IDs: %s, Reads: %s, Writes: %s, Read/Write: %s)\n",
+			color.New(color.FgCyan, color.Underline).Sprint(":"+name), emphasisInt(len(ids)), emphasisInt(reads), emphasisInt(writes), color.New(color.Bold).Sprint(readWriteRatio(reads, writes)))
+
+		endpoints := maps.Keys(result.Endpoints[name])
+		slices.Sort(endpoints)
+		for _, e := range endpoints {
+			fmt.Fprintf(cmd.OutOrStdout(), "\t%s (Count: %s)\n", color.HiBlueString(e), emphasisInt(result.Endpoints[name][e]))
+		}
+
+		if wr := result.WriteToRead(name); len(wr) > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "\tWrite to next read (Count: %s, P50: %s, P90: %s, P99: %s, Max: %s)\n", emphasisInt(len(wr)),
+				internal.DurationQuantile(wr, 0.5), internal.DurationQuantile(wr, 0.9), internal.DurationQuantile(wr, 0.99), wr[len(wr)-1])
+		}
+
+		for i, id := range ids {
+			if i >= displayNum {
+				fmt.Fprintf(cmd.OutOrStdout(), "\t\t... and %s more\n", humanize.Comma(int64(len(ids)-displayNum)))
+				break
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "\t\t%s: Reads %s, Writes %s, Read/Write %s", color.GreenString(id.ID), emphasisInt(id.Reads), emphasisInt(id.Writes), readWriteRatio(id.Reads, id.Writes))
+			if len(id.WriteToRead) > 0 {
+				wr := slices.Clone(id.WriteToRead)
+				slices.Sort(wr)
+				fmt.Fprintf(cmd.OutOrStdout(), " (Write to next read P50: %s)", internal.DurationQuantile(wr, 0.5))
+			}
+			fmt.Fprintln(cmd.OutOrStdout())
+		}
+		fmt.Fprintln(cmd.OutOrStdout())
+	}
+}
+
+func printResourceStat(cmd *cobra.Command, result *internal.Resource, displayNum int, format string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+	t.AppendHeader(table.Row{"Resource", "ID", "Reads", "Writes", "Read/Write", "Write to read count", "Write to read P50(s)", "Write to read max(s)"})
+	for _, name := range result.Names {
+		for i, id := range result.SortedIDs(name) {
+			if i >= displayNum {
+				break
+			}
+			p50, maxWR := "", ""
+			if len(id.WriteToRead) > 0 {
+				wr := slices.Clone(id.WriteToRead)
+				slices.Sort(wr)
+				p50 = fmt.Sprintf("%.3f", internal.DurationQuantile(wr, 0.5).Seconds())
+				maxWR = fmt.Sprintf("%.3f", wr[len(wr)-1].Seconds())
+			}
+			t.AppendRow(table.Row{":" + name, id.ID, humanize.Comma(int64(id.Reads)), humanize.Comma(int64(id.Writes)), readWriteRatio(id.Reads, id.Writes), humanize.Comma(int64(len(id.WriteToRead))), p50, maxWR})
+		}
+	}
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 3, Align: text.AlignRight}, {Number: 4, Align: text.AlignRight}, {Number: 5, Align: text.AlignRight}, {Number: 6, Align: text.AlignRight}, {Number: 7, Align: text.AlignRight}, {Number: 8, Align: text.AlignRight}})
+
+	if format == "csv" {
+		t.RenderCSV()
+	} else if format == "tsv" {
+		t.RenderTSV()
+	} else if format == "table" {
+		t.Render()
+	} else if format == "md" {
+		t.RenderMarkdown()
+	}
+}
+
+// readWriteRatio returns reads / writes, or "-" if there is no write
+func readWriteRatio(reads, writes int) string {
+	if writes == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", float64(reads)/float64(writes))
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/haijima/stool/internal"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResourceCmd(t *testing.T) {
+	p := internal.NewResourceProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewResourceCmd(p, v, fs)
+
+	assert.Equal(t, "resource", cmd.Name(), "NewResourceCmd() should return command named \"resource\". but: %q", cmd.Name())
+}
+
+func TestNewResourceCmd_RunE(t *testing.T) {
+	p := internal.NewResourceProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewResourceCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("matching_groups", []string{"^/api/isu/(?P<jia_isu_uuid>[^/]+)$", "^/api/condition/(?P<jia_isu_uuid>[^/]+)$"})
+	v.Set("no_color", true)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/isu/a HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:01 +0900\treq:POST /api/condition/a HTTP/2.0\tstatus:202\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:04 +0900\treq:GET /api/isu/a HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:05 +0900\treq:GET /api/isu/b HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, ":jia_isu_uuid (IDs: 2, Reads: 3, Writes: 1, Read/Write: 3.00)\n"+
+		"\tGET ^/api/isu/(?P<jia_isu_uuid>[^/]+)$ (Count: 3)\n"+
+		"\tPOST ^/api/condition/(?P<jia_isu_uuid>[^/]+)$ (Count: 1)\n"+
+		"\tWrite to next read (Count: 1, P50: 3s, P90: 3s, P99: 3s, Max: 3s)\n"+
+		"\t\ta: Reads 2, Writes 1, Read/Write 2.00 (Write to next read P50: 3s)\n"+
+		"\t\tb: Reads 1, Writes 0, Read/Write -\n\n", stdout.String())
+}
+
+func TestNewResourceCmd_RunE_stat(t *testing.T) {
+	p := internal.NewResourceProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewResourceCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("matching_groups", []string{"^/api/isu/(?P<jia_isu_uuid>[^/]+)$", "^/api/condition/(?P<jia_isu_uuid>[^/]+)$"})
+	v.Set("stat", true)
+	v.Set("format", "csv")
+	v.Set("num", 1)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/isu/a HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:01 +0900\treq:POST /api/condition/a HTTP/2.0\tstatus:202\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:04 +0900\treq:GET /api/isu/a HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:05 +0900\treq:GET /api/isu/b HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Resource,ID,Reads,Writes,Read/Write,Write to read count,Write to read P50(s),Write to read max(s)\n:jia_isu_uuid,a,2,1,2.00,1,3.000,3.000\n", stdout.String())
+}
+
+func TestNewResourceCmd_RunE_NoNamedGroup(t *testing.T) {
+	p := internal.NewResourceProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewResourceCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("matching_groups", []string{"^/api/isu/([^/]+)$"})
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/isu/a HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n"), 0777)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.ErrorContains(t, err, "no request matches a named capture group")
+}
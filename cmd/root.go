@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"log/slog"
 
 	"github.com/fatih/color"
@@ -13,6 +14,9 @@ import (
 
 var Lv slog.LevelVar
 
+// profileWriterAnnotation marks a command that writes into the profile given by --profile instead of reading from it
+const profileWriterAnnotation = "stool/profile-writer"
+
 // NewRootCmd returns the base command used when called without any subcommands
 func NewRootCmd(v *viper.Viper, fs afero.Fs) *cobra.Command {
 	rootCmd := cobrax.NewRoot(v)
@@ -25,7 +29,13 @@ func NewRootCmd(v *viper.Viper, fs afero.Fs) *cobra.Command {
 		// Set Log level
 		Lv.Set(cobrax.VerbosityLevel(v))
 
-		return cobrax.RootPersistentPreRunE(cmd, v, fs, args)
+		if err := cobrax.RootPersistentPreRunE(cmd, v, fs, args); err != nil {
+			return err
+		}
+		if profile := v.GetString("profile"); profile != "" && cmd.Annotations[profileWriterAnnotation] == "" {
+			return bindProfile(v, profile, cmd.Name())
+		}
+		return nil
 	}
 
 	rootCmd.PersistentFlags().StringP("file", "f", "", "access log file to profile")
@@ -33,13 +43,41 @@ func NewRootCmd(v *viper.Viper, fs afero.Fs) *cobra.Command {
 	rootCmd.PersistentFlags().String("time_format", "02/Jan/2006:15:04:05 -0700", "format to parse time field on log file")
 	rootCmd.PersistentFlags().StringToString("log_labels", map[string]string{}, "comma-separated list of key=value pairs to override log labels")
 	rootCmd.PersistentFlags().String("filter", "", "filter log lines by regular expression")
+	rootCmd.PersistentFlags().String("profile", "", "name of the profile in the config file to use")
 	_ = rootCmd.MarkFlagFilename("file", viper.SupportedExts...)
 
 	rootCmd.AddCommand(NewTrendCmd(internal.NewTrendProfiler(), v, fs))
 	rootCmd.AddCommand(NewTransitionCmd(internal.NewTransitionProfiler(), v, fs))
 	rootCmd.AddCommand(NewScenarioCmd(internal.NewScenarioProfiler(), v, fs))
 	rootCmd.AddCommand(NewParamCmd(internal.NewParamProfiler(), v, fs))
+	rootCmd.AddCommand(NewOpenAPICmd(internal.NewParamProfiler(), v, fs))
+	rootCmd.AddCommand(NewCoverageCmd(internal.NewParamProfiler(), v, fs))
+	rootCmd.AddCommand(NewCacheCmd(internal.NewCacheProfiler(), v, fs))
+	rootCmd.AddCommand(NewResourceCmd(internal.NewResourceProfiler(), v, fs))
+	rootCmd.AddCommand(NewDuplicateCmd(internal.NewDuplicateProfiler(), v, fs))
+	rootCmd.AddCommand(NewReplayCmd(internal.NewReplayer(), v, fs))
 	rootCmd.AddCommand(NewGenConfCmd(v, fs))
 
 	return rootCmd
 }
+
+// bindProfile merges "profiles.<profile>" and then "profiles.<profile>.<cmdName>" of the config file into v.
+// Values given by flags still take precedence over the merged values.
+func bindProfile(v *viper.Viper, profile, cmdName string) error {
+	key := "profiles." + profile
+	conf := v.GetStringMap(key)
+	if len(conf) == 0 {
+		return fmt.Errorf("profile %q is not found in the config file", profile)
+	}
+	if err := v.MergeConfigMap(conf); err != nil {
+		return err
+	}
+	if subConf := v.GetStringMap(key + "." + cmdName); len(subConf) > 0 {
+		if err := v.MergeConfigMap(subConf); err != nil {
+			return err
+		}
+	}
+	slog.Info(fmt.Sprintf("using profile: %s", profile))
+	slog.Debug(cobrax.DebugViper(v))
+	return nil
+}
@@ -15,7 +15,7 @@ func TestNewRootCmd(t *testing.T) {
 
 	assert.Equal(t, "stool", cmd.Name(), "NewRootCommand() should return command named \"stool\". but: %q", cmd.Name())
 	assert.False(t, cmd.HasParent(), "RootCommand should not have parent command.")
-	assert.Equal(t, 5, len(cmd.Commands()), "RootCommand should have 1 sub command. but: %d", len(cmd.Commands()))
+	assert.Equal(t, 11, len(cmd.Commands()), "RootCommand should have %d sub commands. but: %d", 11, len(cmd.Commands()))
 	assert.False(t, cmd.Runnable(), "RootCommand should not runnable.")
 }
 
@@ -81,6 +81,37 @@ func TestNewRootCmd_ConfigFile(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestNewRootCmd_Profile(t *testing.T) {
+	v, fs := createViperAndFs()
+	cmd := NewRootCmd(v, fs)
+
+	_ = afero.WriteFile(fs, ".stool.yaml", []byte("time_format: 2006-01-02T15:04:05Z07:00\ntrend:\n  interval: 10\nprofiles:\n  webapp:\n    matching_groups: [\"^/api/users/[^/]+$\"]\n    trend:\n      sort: [\"count0:asc\"]\n"), 0644)
+
+	trendCmd, _, _ := cmd.Find([]string{"trend"})
+	trendCmd.RunE = func(cmd *cobra.Command, args []string) error { return nil } // dummy function not to read log
+	cmd.SetArgs([]string{"trend", "--config", ".stool.yaml", "--profile", "webapp", "--interval", "30"})
+	err := cmd.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"^/api/users/[^/]+$"}, v.GetStringSlice("matching_groups"))
+	assert.Equal(t, "2006-01-02T15:04:05Z07:00", v.GetString("time_format"))
+	assert.Equal(t, []string{"count0:asc"}, v.GetStringSlice("sort"))
+	assert.Equal(t, 30, v.GetInt("interval"), "flag should take precedence over the profile")
+}
+
+func TestNewRootCmd_Profile_NotFound(t *testing.T) {
+	v, fs := createViperAndFs()
+	cmd := NewRootCmd(v, fs)
+
+	_ = afero.WriteFile(fs, ".stool.yaml", []byte("profiles:\n  webapp:\n    time_format: 2006-01-02\n"), 0644)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {} // dummy function to make command runnable
+	cmd.SetArgs([]string{"--config", ".stool.yaml", "--profile", "admin"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "profile \"admin\" is not found")
+}
+
 func TestExecute(t *testing.T) {
 	v, fs := createViperAndFs()
 	cmd := NewRootCmd(v, fs)
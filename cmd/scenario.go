@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"bytes"
+	"cmp"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -16,6 +21,7 @@ import (
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/exp/maps"
 )
 
 // NewScenarioCmd returns the scenario command
@@ -28,8 +34,17 @@ func NewScenarioCmd(p *internal.ScenarioProfiler, v *viper.Viper, fs afero.Fs) *
 		return runScenario(cmd, v, fs, p)
 	}
 
-	scenarioCmd.Flags().String("format", "dot", "The output format {dot|mermaid|csv}")
+	scenarioCmd.Flags().String("format", "dot", "The output format {dot|mermaid|mermaid-sequence|plantuml|csv|json|k6|go}")
+	scenarioCmd.Flags().Duration("idle_timeout", 0, "Start a new session when a user is idle longer than the timeout. 0 means no timeout")
+	scenarioCmd.Flags().Bool("uidgot_session", false, "Start a session for a user who is first seen via uidgot. Unlike the other commands, it is false by default because such a session lacks its beginning and makes a partial scenario")
+	scenarioCmd.Flags().Duration("drop_start_near_end", 0, "Drop sessions that start within the duration before the end of the log")
+	scenarioCmd.Flags().Float64("drop_start_near_end_ratio", internal.DefaultStartNearEndRatio, "Drop sessions that start within the ratio of the period of the log before the end of the log. 0 means no drop")
+	scenarioCmd.Flags().Duration("drop_active_at_end", 0, "Drop sessions whose last request is within the duration before the end of the log")
+	scenarioCmd.Flags().Duration("drop_start_near_begin", 0, "Drop sessions that start within the duration after the beginning of the log without a new uid set")
+	scenarioCmd.Flags().Float64("similarity", 0, "Cluster scenarios whose endpoint sequences are similar at least the threshold from 0 to 1. 0 means no clustering")
 	scenarioCmd.Flags().Bool("palette", false, "use color palette for each endpoint")
+	scenarioCmd.Flags().String("out_dir", "", "The directory to generate the Go package into with go format")
+	scenarioCmd.Flags().String("package", "scenario", "The package name of the generated Go package with go format")
 
 	return scenarioCmd
 }
@@ -39,37 +54,94 @@ func runScenario(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.Sc
 	timeFormat := v.GetString("time_format")
 	labels := v.GetStringMapString("log_labels")
 	filter := v.GetString("filter")
-	format := v.GetString("format")
+	format := strings.ToLower(v.GetString("format"))
+	sessionOpt := internal.SessionOption{
+		IdleTimeout:   v.GetDuration("idle_timeout"),
+		StartOnUidGot: v.GetBool("uidgot_session"),
+	}
+	incompleteOpt := internal.IncompleteSessionOption{
+		StartNearEnd:      v.GetDuration("drop_start_near_end"),
+		StartNearEndRatio: v.GetFloat64("drop_start_near_end_ratio"),
+		ActiveAtEnd:       v.GetDuration("drop_active_at_end"),
+		StartNearBegin:    v.GetDuration("drop_start_near_begin"),
+	}
+	if incompleteOpt.StartNearEndRatio < 0 || incompleteOpt.StartNearEndRatio >= 1 {
+		return fmt.Errorf("drop_start_near_end_ratio flag should be 0 or more and less than 1. but: %g", incompleteOpt.StartNearEndRatio)
+	}
+	if incompleteOpt.StartNearEndRatio == 0 {
+		incompleteOpt.StartNearEndRatio = -1 // zero ratio means no drop in the flag
+	}
+	similarity := v.GetFloat64("similarity")
 	palette := v.GetBool("palette")
 
+	if similarity < 0 || similarity > 1 {
+		return fmt.Errorf("similarity flag should be between 0 and 1. but: %g", similarity)
+	}
+
 	f, err := cobrax.OpenOrStdIn(v.GetString("file"), fs, cobrax.WithStdin(cmd.InOrStdin()))
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	logReader, err := log.NewLTSVReader(f, log.LTSVReadOpt{
+	readOpt := log.LTSVReadOpt{
 		MatchingGroups: matchingGroups,
 		TimeFormat:     timeFormat,
 		Labels:         labels,
 		Filter:         filter,
-	})
+	}
+
+	// the code generators fill path parameters with the values observed by the param profiler, so read the log twice
+	var in io.Reader = f
+	var param *internal.Param
+	if format == "k6" || format == "go" {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		paramReader, err := log.NewLTSVReader(bytes.NewReader(b), readOpt)
+		if err != nil {
+			return err
+		}
+		param, err = internal.NewParamProfiler().Profile(paramReader)
+		if err != nil {
+			return err
+		}
+		in = bytes.NewReader(b)
+	}
+
+	logReader, err := log.NewLTSVReader(in, readOpt)
 	if err != nil {
 		return err
 	}
 
-	scenarios, err := p.Profile(logReader)
+	scenarios, dropped, err := p.Profile(logReader, internal.ScenarioOption{SessionOption: sessionOpt, IncompleteSessionOption: incompleteOpt, Similarity: similarity})
 	if err != nil {
 		return err
 	}
+	printIncompleteSessions(cmd, incompleteOpt, dropped)
 
 	var printFn printScenarioFunc
-	switch strings.ToLower(format) {
+	switch format {
 	case "dot":
 		printFn = createScenarioDot
 	case "csv":
 		printFn = printScenarioCSV
 	case "mermaid":
 		printFn = createScenarioMermaid
+	case "mermaid-sequence":
+		printFn = printScenarioMermaidSequence
+	case "plantuml":
+		printFn = printScenarioPlantUML
+	case "json":
+		printFn = printScenarioJSON
+	case "k6":
+		printFn = func(cmd *cobra.Command, scenarioStructs []internal.ScenarioStruct, _ bool) error {
+			return printScenarioK6(cmd, scenarioStructs, param)
+		}
+	case "go":
+		printFn = func(cmd *cobra.Command, scenarioStructs []internal.ScenarioStruct, _ bool) error {
+			return printScenarioGo(cmd, fs, v.GetString("out_dir"), v.GetString("package"), scenarioStructs, param)
+		}
 	default:
 		return fmt.Errorf("invalid format flag: %s", format)
 	}
@@ -77,23 +149,77 @@ func runScenario(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.Sc
 	return printFn(cmd, scenarios, palette)
 }
 
+// printIncompleteSessions reports the number of sessions dropped by each enabled rule to stderr
+func printIncompleteSessions(cmd *cobra.Command, opt internal.IncompleteSessionOption, dropped internal.IncompleteSessions) {
+	if opt.StartNearEnd > 0 && opt.StartNearEndRatio > 0 {
+		cmd.PrintErrf("Dropped %s sessions that start within %s or %g%% of the period before the end of the log\n", humanize.Comma(int64(dropped.StartNearEnd)), opt.StartNearEnd, opt.StartNearEndRatio*100)
+	} else if opt.StartNearEnd > 0 {
+		cmd.PrintErrf("Dropped %s sessions that start within %s before the end of the log\n", humanize.Comma(int64(dropped.StartNearEnd)), opt.StartNearEnd)
+	} else if opt.StartNearEndRatio > 0 {
+		cmd.PrintErrf("Dropped %s sessions that start within %g%% of the period before the end of the log\n", humanize.Comma(int64(dropped.StartNearEnd)), opt.StartNearEndRatio*100)
+	}
+	if opt.ActiveAtEnd > 0 {
+		cmd.PrintErrf("Dropped %s sessions that are active within %s before the end of the log\n", humanize.Comma(int64(dropped.ActiveAtEnd)), opt.ActiveAtEnd)
+	}
+	if opt.StartNearBegin > 0 {
+		cmd.PrintErrf("Dropped %s sessions that start within %s after the beginning of the log\n", humanize.Comma(int64(dropped.StartNearBegin)), opt.StartNearBegin)
+	}
+}
+
 type printScenarioFunc = func(*cobra.Command, []internal.ScenarioStruct, bool) error
 
 func printScenarioCSV(cmd *cobra.Command, scenarioStructs []internal.ScenarioStruct, usePalette bool) error {
 	writer := csv.NewWriter(cmd.OutOrStdout())
 
 	// header
-	_ = writer.Write([]string{"first call[s]", "last call[s]", "count", "scenario node"})
+	_ = writer.Write([]string{"first call[s]", "last call[s]", "count", "scenario node", "duration p50[ms]", "duration p90[ms]", "requests", "requests p50", "requests max", "think time p50/p90 per edge[ms]"})
 
 	// data rows
 	for _, s := range scenarioStructs {
-		_ = writer.Write([]string{strconv.Itoa(s.FirstReq), strconv.Itoa(s.LastReq), strconv.Itoa(s.Count), s.Pattern.String(true)})
+		timing := s.Timing()
+		edges := maps.Keys(timing.ThinkTime)
+		slices.SortFunc(edges, compareScenarioEdge)
+		thinkTimes := make([]string, 0, len(edges))
+		for _, e := range edges {
+			stats := timing.ThinkTime[e]
+			thinkTimes = append(thinkTimes, fmt.Sprintf("%d->%d:%d/%d", e.From, e.To, stats.P50.Milliseconds(), stats.P90.Milliseconds()))
+		}
+		_ = writer.Write([]string{strconv.Itoa(s.FirstReq), strconv.Itoa(s.LastReq), strconv.Itoa(s.Count), s.Pattern.String(true),
+			strconv.FormatInt(timing.Duration.P50.Milliseconds(), 10), strconv.FormatInt(timing.Duration.P90.Milliseconds(), 10),
+			strconv.Itoa(timing.Requests.Total), strconv.Itoa(timing.Requests.P50), strconv.Itoa(timing.Requests.Max), strings.Join(thinkTimes, ";")})
 	}
 
 	writer.Flush()
 	return nil
 }
 
+func compareScenarioEdge(a, b internal.ScenarioEdge) int {
+	if a.From != b.From {
+		return cmp.Compare(a.From, b.From)
+	}
+	return cmp.Compare(a.To, b.To)
+}
+
+func printScenarioJSON(cmd *cobra.Command, scenarioStructs []internal.ScenarioStruct, usePalette bool) error {
+	report := ScenarioReport{Scenarios: make([]ScenarioStructReport, 0, len(scenarioStructs))}
+	for _, s := range scenarioStructs {
+		report.Scenarios = append(report.Scenarios, ScenarioStructReport{
+			Pattern:  s.Pattern.String(true),
+			Count:    s.Count,
+			Members:  s.Members,
+			FirstReq: s.FirstReq,
+			LastReq:  s.LastReq,
+			Tree:     newPatternReport(*s.Pattern),
+			Timing:   newScenarioTimingReport(s),
+		})
+	}
+
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false) // keep "->" of patterns readable
+	return enc.Encode(report)
+}
+
 func createScenarioDot(cmd *cobra.Command, scenarioStructs []internal.ScenarioStruct, usePalette bool) error {
 	graph := graphviz.NewGraph("root", "stool scenario")
 	graph.IsHorizontal = true
@@ -103,38 +229,24 @@ func createScenarioDot(cmd *cobra.Command, scenarioStructs []internal.ScenarioSt
 		sumCount += scenario.Count
 	}
 
-	palette := make(map[string]string, 0)
-	if usePalette {
-		// create palette
-		for _, scenarioStruct := range scenarioStructs {
-			for _, s := range pattern.Flatten([]pattern.Node{*scenarioStruct.Pattern}, make([]string, scenarioStruct.Pattern.Leaves())) {
-				if _, ok := palette[s]; !ok {
-					palette[s] = ""
-				}
-			}
-		}
-		p, err := colorful.HappyPalette(len(palette))
-		if err != nil {
-			return err
-		}
-		i := 0
-		for k := range palette {
-			palette[k] = p[i].Hex()
-			i++
-		}
+	palette, err := scenarioPalette(scenarioStructs, usePalette)
+	if err != nil {
+		return err
 	}
 
 	for i, scenario := range scenarioStructs {
 		subGraphName := fmt.Sprintf("cluster_%d", i)
-		subGraphTitle := fmt.Sprintf("Scenario #%d  (count: %s, req: %d - %d [s])", i+1, humanize.Comma(int64(scenario.Count)), scenario.FirstReq, scenario.LastReq)
+		timing := scenario.Timing()
+		subGraphTitle := scenarioTitle(i, scenario, timing)
 		subGraph := graphviz.NewGraph(subGraphName, subGraphTitle)
 		if err := graph.AddSubGraph(subGraph); err != nil {
 			return err
 		}
 
 		nodes := map[int]string{}
+		optional := map[int]bool{}
 		edges := make([]edge, 0, scenario.Pattern.Leaves())
-		patternToNodeAndEdge(*scenario.Pattern, nodes, &edges, 0)
+		first, last, _ := patternToNodeAndEdge(*scenario.Pattern, nodes, optional, &edges, 0, true)
 
 		for _, v := range []string{"start", "end"} {
 			node := graphviz.NewTextNode(fmt.Sprintf("%d-%s", i, v), v)
@@ -145,6 +257,7 @@ func createScenarioDot(cmd *cobra.Command, scenarioStructs []internal.ScenarioSt
 		for j, v := range nodes {
 			node := graphviz.NewBoxNode(fmt.Sprintf("%d-%d", i, j), v)
 			node.SetColorLevel(scenario.Count, sumCount)
+			node.Dashed = optional[j]
 			if usePalette {
 				node.FillColor = palette[v]
 			}
@@ -155,25 +268,30 @@ func createScenarioDot(cmd *cobra.Command, scenarioStructs []internal.ScenarioSt
 
 		penWidth := float64(scenario.Count)*10/float64(sumCount) + 1
 
-		startEdge := graphviz.NewEdge(fmt.Sprintf("%d-start", i), fmt.Sprintf("%d-%d", i, 0))
-		startEdge.SetColorLevel(scenario.Count, sumCount)
-		startEdge.PenWidth = penWidth
-		startEdge.Weight = 1000
-		if err := subGraph.AddEdge(startEdge); err != nil {
-			return err
+		for _, f := range first {
+			startEdge := graphviz.NewEdge(fmt.Sprintf("%d-start", i), fmt.Sprintf("%d-%d", i, f))
+			startEdge.SetColorLevel(scenario.Count, sumCount)
+			startEdge.PenWidth = penWidth
+			startEdge.Weight = 1000
+			if err := subGraph.AddEdge(startEdge); err != nil {
+				return err
+			}
 		}
-		endEdge := graphviz.NewEdge(fmt.Sprintf("%d-%d", i, scenario.Pattern.Leaves()-1), fmt.Sprintf("%d-end", i))
-		endEdge.SetColorLevel(scenario.Count, sumCount)
-		endEdge.PenWidth = penWidth
-		endEdge.Weight = 1000
-		if err := subGraph.AddEdge(endEdge); err != nil {
-			return err
+		for _, l := range last {
+			endEdge := graphviz.NewEdge(fmt.Sprintf("%d-%d", i, l), fmt.Sprintf("%d-end", i))
+			endEdge.SetColorLevel(scenario.Count, sumCount)
+			endEdge.PenWidth = penWidth
+			endEdge.Weight = 1000
+			if err := subGraph.AddEdge(endEdge); err != nil {
+				return err
+			}
 		}
 
 		for _, edge := range edges {
 			e := graphviz.NewEdge(fmt.Sprintf("%d-%d", i, edge.From), fmt.Sprintf("%d-%d", i, edge.To))
 			e.SetColorLevel(scenario.Count, sumCount)
 			e.PenWidth = penWidth
+			e.Label = thinkTimeLabel(timing, edge)
 			if edge.From < edge.To {
 				e.Weight = 1000
 			}
@@ -192,95 +310,126 @@ func createScenarioMermaid(cmd *cobra.Command, scenarioStructs []internal.Scenar
 	cmd.Println("---")
 	cmd.Println("flowchart LR")
 
-	graph := graphviz.NewGraph("root", "stool scenario")
-	graph.IsHorizontal = true
-
 	sumCount := 0
 	for _, scenario := range scenarioStructs {
 		sumCount += scenario.Count
 	}
 
-	palette := make(map[string]string, 0)
-	if usePalette {
-		// create palette
-		for _, scenarioStruct := range scenarioStructs {
-			for _, s := range pattern.Flatten([]pattern.Node{*scenarioStruct.Pattern}, make([]string, scenarioStruct.Pattern.Leaves())) {
-				if _, ok := palette[s]; !ok {
-					palette[s] = ""
-				}
-			}
-		}
-		p, err := colorful.HappyPalette(len(palette))
-		if err != nil {
-			return err
-		}
-		i := 0
-		for k := range palette {
-			palette[k] = p[i].Hex()
-			i++
-		}
+	palette, err := scenarioPalette(scenarioStructs, usePalette)
+	if err != nil {
+		return err
 	}
 
+	link := 0
 	for i, scenario := range scenarioStructs {
-		subGraphName := fmt.Sprintf("cluster_%d", i)
-		subGraphTitle := fmt.Sprintf("Scenario #%d  (count: %s, req: %d - %d [s])", i+1, humanize.Comma(int64(scenario.Count)), scenario.FirstReq, scenario.LastReq)
-		subGraph := graphviz.NewGraph(subGraphName, subGraphTitle)
-		if err := graph.AddSubGraph(subGraph); err != nil {
-			return err
-		}
+		timing := scenario.Timing()
+		cmd.Printf("\tsubgraph s%d [%s]\n", i, mermaidLabel(scenarioTitle(i, scenario, timing)))
+		cmd.Println("\t\tdirection LR")
 
 		nodes := map[int]string{}
+		optional := map[int]bool{}
 		edges := make([]edge, 0, scenario.Pattern.Leaves())
-		patternToNodeAndEdge(*scenario.Pattern, nodes, &edges, 0)
+		first, last, _ := patternToNodeAndEdge(*scenario.Pattern, nodes, optional, &edges, 0, true)
 
-		for _, v := range []string{"start", "end"} {
-			node := graphviz.NewTextNode(fmt.Sprintf("%d-%s", i, v), v)
-			if err := subGraph.AddTextNode(node); err != nil {
-				return err
+		indices := maps.Keys(nodes)
+		slices.Sort(indices)
+
+		cmd.Printf("\t\ts%d_start([start])\n", i)
+		cmd.Printf("\t\ts%d_end([end])\n", i)
+		for _, j := range indices {
+			class := ""
+			if optional[j] {
+				class = ":::optional"
 			}
+			cmd.Printf("\t\ts%d_%d[%s]%s\n", i, j, mermaidLabel(nodes[j]), class)
 		}
-		for j, v := range nodes {
-			node := graphviz.NewBoxNode(fmt.Sprintf("%d-%d", i, j), v)
-			node.SetColorLevel(scenario.Count, sumCount)
-			if usePalette {
-				node.FillColor = palette[v]
-			}
-			if err := subGraph.AddBoxNode(node); err != nil {
-				return err
+
+		links := make([]string, 0, len(first)+len(last)+len(edges))
+		for _, f := range first {
+			cmd.Printf("\t\ts%d_start --> s%d_%d\n", i, i, f)
+			links = append(links, strconv.Itoa(link+len(links)))
+		}
+		for _, e := range edges {
+			if label := thinkTimeLabel(timing, e); label != "" {
+				cmd.Printf("\t\ts%d_%d -->|%s| s%d_%d\n", i, e.From, mermaidLabel(label), i, e.To)
+			} else {
+				cmd.Printf("\t\ts%d_%d --> s%d_%d\n", i, e.From, i, e.To)
 			}
+			links = append(links, strconv.Itoa(link+len(links)))
+		}
+		for _, l := range last {
+			cmd.Printf("\t\ts%d_%d --> s%d_end\n", i, l, i)
+			links = append(links, strconv.Itoa(link+len(links)))
 		}
+		link += len(links)
+		cmd.Println("\tend")
 
 		penWidth := float64(scenario.Count)*10/float64(sumCount) + 1
-
-		startEdge := graphviz.NewEdge(fmt.Sprintf("%d-start", i), fmt.Sprintf("%d-%d", i, 0))
-		startEdge.SetColorLevel(scenario.Count, sumCount)
-		startEdge.PenWidth = penWidth
-		startEdge.Weight = 1000
-		if err := subGraph.AddEdge(startEdge); err != nil {
-			return err
-		}
-		endEdge := graphviz.NewEdge(fmt.Sprintf("%d-%d", i, scenario.Pattern.Leaves()-1), fmt.Sprintf("%d-end", i))
-		endEdge.SetColorLevel(scenario.Count, sumCount)
-		endEdge.PenWidth = penWidth
-		endEdge.Weight = 1000
-		if err := subGraph.AddEdge(endEdge); err != nil {
-			return err
+		cmd.Printf("\tlinkStyle %s stroke-width:%.1fpx\n", strings.Join(links, ","), penWidth)
+		if usePalette {
+			for _, j := range indices {
+				cmd.Printf("\tstyle s%d_%d fill:%s\n", i, j, palette[nodes[j]])
+			}
 		}
+	}
+	cmd.Println("\tclassDef optional stroke-dasharray: 5 5")
 
-		for _, edge := range edges {
-			e := graphviz.NewEdge(fmt.Sprintf("%d-%d", i, edge.From), fmt.Sprintf("%d-%d", i, edge.To))
-			e.SetColorLevel(scenario.Count, sumCount)
-			e.PenWidth = penWidth
-			if edge.From < edge.To {
-				e.Weight = 1000
-			}
-			if err := subGraph.AddEdge(e); err != nil {
-				return err
+	return nil
+}
+
+// scenarioPalette returns the colors of the endpoints of the scenarios. It returns an empty map if usePalette is false.
+func scenarioPalette(scenarioStructs []internal.ScenarioStruct, usePalette bool) (map[string]string, error) {
+	palette := make(map[string]string, 0)
+	if !usePalette {
+		return palette, nil
+	}
+	for _, scenarioStruct := range scenarioStructs {
+		for _, s := range pattern.Flatten([]pattern.Node{*scenarioStruct.Pattern}, make([]string, scenarioStruct.Pattern.Leaves())) {
+			if _, ok := palette[s]; !ok {
+				palette[s] = ""
 			}
 		}
 	}
+	p, err := colorful.HappyPalette(len(palette))
+	if err != nil {
+		return nil, err
+	}
+	i := 0
+	for k := range palette {
+		palette[k] = p[i].Hex()
+		i++
+	}
+	return palette, nil
+}
 
-	return graph.Write(cmd.OutOrStdout())
+// mermaidLabel quotes the text for a label of a node, a subgraph or an edge of a Mermaid flowchart
+func mermaidLabel(s string) string {
+	return "\"" + strings.ReplaceAll(mermaidText(s), "\"", "#quot;") + "\""
+}
+
+// scenarioTitle returns the title of the scenario with the count, the time range and the statistics of the sessions
+func scenarioTitle(i int, scenario internal.ScenarioStruct, timing internal.ScenarioTiming) string {
+	return fmt.Sprintf("Scenario #%d  (%s)", i+1, scenarioSummary(scenario, timing))
+}
+
+// scenarioSummary returns the count, the time range and the statistics of the sessions of the scenario
+func scenarioSummary(scenario internal.ScenarioStruct, timing internal.ScenarioTiming) string {
+	members := ""
+	if scenario.Members > 1 {
+		members = fmt.Sprintf(", members: %d", scenario.Members)
+	}
+	return fmt.Sprintf("count: %s%s, req: %d - %d [ms], duration: p50 %s / p90 %s, requests: p50 %d / max %d",
+		humanize.Comma(int64(scenario.Count)), members, scenario.FirstReq, scenario.LastReq,
+		timing.Duration.P50, timing.Duration.P90, timing.Requests.P50, timing.Requests.Max)
+}
+
+// thinkTimeLabel returns the quantiles of the think time on the edge. It returns an empty string if no transition is observed.
+func thinkTimeLabel(timing internal.ScenarioTiming, e edge) string {
+	stats, ok := timing.ThinkTime[internal.ScenarioEdge{From: e.From, To: e.To}]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("p50 %s / p90 %s", stats.P50, stats.P90)
 }
 
 type edge struct {
@@ -288,23 +437,174 @@ type edge struct {
 	To   int
 }
 
-func patternToNodeAndEdge(n pattern.Node, nodes map[int]string, edges *[]edge, base int) {
+// patternToNodeAndEdge collects the leaves of the pattern into nodes by the index and the transitions between them into edges.
+// Leaves in optional groups are marked in optional.
+// It returns the indices of the leaves which can be the first and the last of the node, and whether the node can be skipped.
+func patternToNodeAndEdge(n pattern.Node, nodes map[int]string, optional map[int]bool, edges *[]edge, base int, root bool) ([]int, []int, bool) {
 	if n.IsLeaf() {
 		nodes[base] = n.Value()
-		return
+		return []int{base}, []int{base}, false
 	}
 
+	first, last := make([]int, 0), make([]int, 0)
+	skippable := n.Kind() != pattern.Alternation
 	offset := 0
-	for i, child := range n.Children() {
-		patternToNodeAndEdge(child, nodes, edges, base+offset)
+	for _, child := range n.Children() {
+		f, l, s := patternToNodeAndEdge(child, nodes, optional, edges, base+offset, false)
 		offset += child.Leaves()
-		if i < n.Degree()-1 {
-			*edges = append(*edges, edge{From: base + offset - 1, To: base + offset})
+		if n.Kind() == pattern.Alternation {
+			first = append(first, f...)
+			last = append(last, l...)
+			skippable = skippable || s
+			continue
+		}
+		for _, from := range last {
+			for _, to := range f {
+				addEdge(edges, edge{From: from, To: to})
+			}
+		}
+		if skippable {
+			first = append(first, f...)
+		}
+		if s {
+			last = append(last, l...)
 		} else {
-			if base == 0 && !n.IsLeaf() && (n.Degree() > 1 || n.Child(0).IsLeaf()) {
-				continue
+			last = slices.Clone(l)
+		}
+		skippable = skippable && s
+	}
+
+	switch n.Kind() {
+	case pattern.Optional:
+		skippable = true
+		for i := base; i < base+n.Leaves(); i++ {
+			optional[i] = true
+		}
+	case pattern.Repeat:
+		if !root {
+			for _, from := range last {
+				for _, to := range first {
+					addEdge(edges, edge{From: from, To: to})
+				}
 			}
-			*edges = append(*edges, edge{From: base + offset - 1, To: base})
 		}
 	}
+	return first, last, skippable
+}
+
+func addEdge(edges *[]edge, e edge) {
+	if !slices.Contains(*edges, e) {
+		*edges = append(*edges, e)
+	}
+}
+
+// ScenarioReport is the machine-readable output of the scenario command
+type ScenarioReport struct {
+	Scenarios []ScenarioStructReport `json:"scenarios"`
+}
+
+type ScenarioStructReport struct {
+	Pattern  string        `json:"pattern"` // string form of the pattern like "A -> (B -> C)*", which can be read by pattern.Parse
+	Count    int           `json:"count"`
+	Members  int           `json:"members"`   // number of the distinct scenarios merged into the scenario by repetitions or --similarity
+	FirstReq int           `json:"first_req"` // milliseconds from the beginning of the log
+	LastReq  int           `json:"last_req"`  // milliseconds from the beginning of the log
+	Tree     PatternReport `json:"tree"`
+	Timing   TimingReport  `json:"timing"`
+}
+
+// TimingReport is the timing statistics of the sessions of a scenario. Durations are in milliseconds.
+type TimingReport struct {
+	Duration   DurationReport    `json:"duration"`
+	Requests   RequestsReport    `json:"requests"`
+	ThinkTimes []ThinkTimeReport `json:"think_times"`
+}
+
+type DurationReport struct {
+	Count int   `json:"count"`
+	Mean  int64 `json:"mean"`
+	P50   int64 `json:"p50"`
+	P90   int64 `json:"p90"`
+	P99   int64 `json:"p99"`
+	Max   int64 `json:"max"`
+}
+
+type RequestsReport struct {
+	Total int     `json:"total"`
+	Mean  float64 `json:"mean"`
+	P50   int     `json:"p50"`
+	P90   int     `json:"p90"`
+	Max   int     `json:"max"`
+}
+
+// ThinkTimeReport is the time between consecutive requests on an edge between the leaves of the pattern tree
+type ThinkTimeReport struct {
+	From int `json:"from"` // index of the leaf in the tree
+	To   int `json:"to"`
+	DurationReport
+}
+
+func newDurationReport(stats internal.DurationStats) DurationReport {
+	return DurationReport{
+		Count: stats.Count,
+		Mean:  stats.Mean.Milliseconds(),
+		P50:   stats.P50.Milliseconds(),
+		P90:   stats.P90.Milliseconds(),
+		P99:   stats.P99.Milliseconds(),
+		Max:   stats.Max.Milliseconds(),
+	}
+}
+
+func newScenarioTimingReport(s internal.ScenarioStruct) TimingReport {
+	timing := s.Timing()
+	r := TimingReport{
+		Duration:   newDurationReport(timing.Duration),
+		Requests:   RequestsReport(timing.Requests),
+		ThinkTimes: make([]ThinkTimeReport, 0, len(timing.ThinkTime)),
+	}
+	edges := maps.Keys(timing.ThinkTime)
+	slices.SortFunc(edges, compareScenarioEdge)
+	for _, e := range edges {
+		r.ThinkTimes = append(r.ThinkTimes, ThinkTimeReport{From: e.From, To: e.To, DurationReport: newDurationReport(timing.ThinkTime[e])})
+	}
+	return r
+}
+
+// PatternReport is a node of the pattern tree. The root and repeat groups have children, and leaves have an endpoint.
+type PatternReport struct {
+	Endpoint    string          `json:"endpoint,omitempty"`
+	Index       int             `json:"index"`                 // index of the first leaf of the subtree in the order of the endpoints
+	Repeat      bool            `json:"repeat"`                // whether the node is a repeat group like "(B -> C)*"
+	Optional    bool            `json:"optional,omitempty"`    // whether the node is an optional group like "B?"
+	Alternation bool            `json:"alternation,omitempty"` // whether the node is an alternation like "(B | C)" whose children are the alternatives
+	Degree      int             `json:"degree"`                // number of the children
+	Leaves      int             `json:"leaves"`                // number of the endpoints in the subtree
+	Children    []PatternReport `json:"children,omitempty"`
+}
+
+func newPatternReport(root pattern.Node) PatternReport {
+	r := patternToReport(root, 0)
+	r.Repeat = false
+	return r
+}
+
+func patternToReport(n pattern.Node, index int) PatternReport {
+	if n.IsLeaf() {
+		return PatternReport{Endpoint: n.Value(), Index: index, Leaves: n.Leaves()}
+	}
+	children := make([]PatternReport, 0, n.Degree())
+	offset := index
+	for _, child := range n.Children() {
+		children = append(children, patternToReport(child, offset))
+		offset += child.Leaves()
+	}
+	return PatternReport{
+		Index:       index,
+		Repeat:      n.Kind() == pattern.Repeat,
+		Optional:    n.Kind() == pattern.Optional,
+		Alternation: n.Kind() == pattern.Alternation,
+		Degree:      n.Degree(),
+		Leaves:      n.Leaves(),
+		Children:    children,
+	}
 }
@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/pattern"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var goScenarioTemplate = template.Must(template.New("scenario.go").Parse(`// Code generated by stool scenario --format go. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+)
+
+// Client issues the requests of the scenarios to the server
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client which requests to the base URL like "http://localhost:8080"
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+type weighted[T any] struct {
+	value  T
+	weight int
+}
+
+// pick chooses a value at random weighted by the weights. It returns the zero value if no weight is positive.
+func pick[T any](ws []weighted[T]) T {
+	sum := 0
+	for _, w := range ws {
+		sum += w.weight
+	}
+	if sum <= 0 {
+		var zero T
+		return zero
+	}
+	r := rand.Intn(sum)
+	for _, w := range ws {
+		r -= w.weight
+		if r < 0 {
+			return w.value
+		}
+	}
+	return ws[len(ws)-1].value
+}
+
+type endpoint struct {
+	method string
+	uri    string // URI or the regular expression of the matching group
+	path   string // path template like "/users/{id}". empty if the matching group cannot be converted into a path
+	params map[string][]weighted[string]
+}
+
+var endpoints = map[string]endpoint{
+{{- range .Endpoints }}
+	{{ printf "%q" .Key }}: {
+		method: {{ printf "%q" .Method }},
+		uri:    {{ printf "%q" .URI }},
+		path:   {{ printf "%q" .Path }},
+		params: map[string][]weighted[string]{
+		{{- range .Params }}
+			{{ printf "%q" .Name }}: { {{- range .Values }}{ {{- printf "%q" .Value }}, {{ .Count }}}, {{ end }}},
+		{{- end }}
+		},
+	},
+{{- end }}
+}
+
+// request issues the request of the endpoint filling its path parameters with the observed values
+func (c *Client) request(ctx context.Context, key string) error {
+	e := endpoints[key]
+	if e.path == "" {
+		return nil
+	}
+	path := e.path
+	for name, values := range e.params {
+		if v := pick(values); v != "" { // the parameter is left as is if no value is observed
+			path = strings.ReplaceAll(path, "{"+name+"}", v)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, e.method, c.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	defer res.Body.Close()
+	_, err = io.Copy(io.Discard, res.Body)
+	return err
+}
+{{ range .Scenarios }}
+// {{ .Name }} follows the scenario observed {{ .Count }} times from {{ .FirstReq }}ms to {{ .LastReq }}ms:
+// {{ .Pattern }}
+func (c *Client) {{ .Name }}(ctx context.Context) error {
+{{ .Body -}}
+	return nil
+}
+{{ end }}
+// scenarios are the scenarios weighted by their observed counts
+var scenarios = []weighted[func(*Client, context.Context) error]{
+{{- range .Scenarios }}
+	{(*Client).{{ .Name }}, {{ .Count }}},
+{{- end }}
+}
+
+// Run runs a scenario chosen at random weighted by the observed counts
+func (c *Client) Run(ctx context.Context) error {
+	if len(scenarios) == 0 {
+		return nil
+	}
+	return pick(scenarios)(c, ctx)
+}
+`))
+
+var goScenarioTestTemplate = template.Must(template.New("scenario_test.go").Parse(`// Code generated by stool scenario --format go. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestScenarios(t *testing.T) {
+	var mu sync.Mutex
+	requests := make([]string, 0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	for i, s := range scenarios {
+		if err := s.value(c, context.Background()); err != nil {
+			t.Errorf("scenario #%d: %v", i+1, err)
+		}
+	}
+	if err := c.Run(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	for _, req := range requests {
+		if !matchEndpoint(req) {
+			t.Errorf("%q doesn't match any endpoint", req)
+		}
+	}
+}
+
+func matchEndpoint(req string) bool {
+	for _, e := range endpoints {
+		if e.path == "" {
+			continue
+		}
+		if e.method+" "+e.uri == req {
+			return true
+		}
+		if re, err := regexp.Compile(e.uri); err == nil && e.uri != e.path && re.MatchString(req[len(e.method)+1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPick(t *testing.T) {
+	if v := pick([]weighted[string]{}); v != "" {
+		t.Errorf("pick should return the zero value without values but: %q", v)
+	}
+	ws := []weighted[int]{
+		{1, 0},
+		{2, 3},
+	}
+	if v := pick(ws); v != 2 {
+		t.Errorf("pick should not choose a value without weight but: %d", v)
+	}
+}
+`))
+
+type goScenarioData struct {
+	Package   string
+	Endpoints []goEndpoint
+	Scenarios []goScenario
+}
+
+type goEndpoint struct {
+	scenarioEndpoint
+	URI string
+}
+
+type goScenario struct {
+	Name     string
+	Count    int
+	FirstReq int
+	LastReq  int
+	Pattern  string
+	Body     string
+}
+
+// writeScenarioGo generates a Go package which issues the requests of the scenarios into the directory
+func writeScenarioGo(fs afero.Fs, dir, pkg string, scenarioStructs []internal.ScenarioStruct, param *internal.Param) error {
+	data := goScenarioData{Package: pkg}
+	for _, e := range newScenarioEndpoints(scenarioStructs, param) {
+		_, uri, _ := strings.Cut(e.Key, " ")
+		data.Endpoints = append(data.Endpoints, goEndpoint{scenarioEndpoint: e, URI: uri})
+	}
+	for i, s := range scenarioStructs {
+		var body strings.Builder
+		group := 0
+		writeGoNodes(&body, s.Pattern.Children(), s.Iterations(), &group, 1)
+		data.Scenarios = append(data.Scenarios, goScenario{
+			Name:     fmt.Sprintf("Scenario%d", i+1),
+			Count:    s.Count,
+			FirstReq: s.FirstReq,
+			LastReq:  s.LastReq,
+			Pattern:  s.Pattern.String(true),
+			Body:     body.String(),
+		})
+	}
+
+	if err := fs.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for name, tmpl := range map[string]*template.Template{"scenario.go": goScenarioTemplate, "scenario_test.go": goScenarioTestTemplate} {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return err
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", name, err)
+		}
+		if err := afero.WriteFile(fs, filepath.Join(dir, name), src, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeGoNodes writes the requests of the nodes like printK6Nodes
+func writeGoNodes(sb *strings.Builder, nodes []pattern.Node, iterations [][]int, group *int, depth int) {
+	indent := strings.Repeat("\t", depth)
+	for _, n := range nodes {
+		if n.IsLeaf() {
+			fmt.Fprintf(sb, "%sif err := c.request(ctx, %q); err != nil {\n%s\treturn err\n%s}\n", indent, n.Value(), indent, indent)
+			continue
+		}
+		weights := make([]string, 0)
+		for _, w := range iterationWeights(n, iterations[*group]) {
+			weights = append(weights, fmt.Sprintf("{%d, %d}", w[0], w[1]))
+		}
+		*group++
+		switch n.Kind() {
+		case pattern.Optional:
+			fmt.Fprintf(sb, "%sif pick([]weighted[int]{%s}) == 1 {\n", indent, strings.Join(weights, ", "))
+			writeGoNodes(sb, n.Children(), iterations, group, depth+1)
+		case pattern.Alternation:
+			fmt.Fprintf(sb, "%sswitch pick([]weighted[int]{%s}) {\n", indent, strings.Join(weights, ", "))
+			for i, alternative := range n.Children() {
+				fmt.Fprintf(sb, "%scase %d:\n", indent, i)
+				writeGoNodes(sb, alternative.Children(), iterations, group, depth+1)
+			}
+		default:
+			v := fmt.Sprintf("i%d", depth)
+			fmt.Fprintf(sb, "%sfor %s := pick([]weighted[int]{%s}); %s > 0; %s-- {\n", indent, v, strings.Join(weights, ", "), v, v)
+			writeGoNodes(sb, n.Children(), iterations, group, depth+1)
+		}
+		fmt.Fprintf(sb, "%s}\n", indent)
+	}
+}
+
+func printScenarioGo(cmd *cobra.Command, fs afero.Fs, dir, pkg string, scenarioStructs []internal.ScenarioStruct, param *internal.Param) error {
+	if dir == "" {
+		return fmt.Errorf("out_dir flag is required for go format")
+	}
+	if err := writeScenarioGo(fs, dir, pkg, scenarioStructs, param); err != nil {
+		return err
+	}
+	cmd.PrintErrf("Generated package %q with %d scenarios in %s\n", pkg, len(scenarioStructs), dir)
+	return nil
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/openapi"
+	"github.com/haijima/stool/internal/pattern"
+	"github.com/spf13/cobra"
+)
+
+// maxParamValues is the maximum number of the observed values of a path parameter to be embedded in the generated code
+const maxParamValues = 100
+
+// maxK6VUs is the maximum number of VUs of a k6 scenario
+const maxK6VUs = 100
+
+// scenarioEndpoint is an endpoint requested in scenarios with the values to fill its path parameters
+type scenarioEndpoint struct {
+	Key    string
+	Method string
+	Path   string // path template like "/users/{id}". empty if the matching group cannot be converted into a path
+	Params []scenarioParam
+}
+
+type scenarioParam struct {
+	Name   string
+	Values []ParamTopValue
+}
+
+// newScenarioEndpoints returns the endpoints requested in the scenarios sorted by the key.
+// Path parameters of the endpoints grouped by matching groups are filled by the values observed by the param profiler.
+func newScenarioEndpoints(scenarioStructs []internal.ScenarioStruct, param *internal.Param) []scenarioEndpoint {
+	keys := make([]string, 0)
+	for _, s := range scenarioStructs {
+		keys = append(keys, pattern.Flatten([]pattern.Node{*s.Pattern}, make([]string, s.Pattern.Leaves()))...)
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	endpoints := make([]scenarioEndpoint, 0, len(keys))
+	for _, k := range keys {
+		method, uri, _ := strings.Cut(k, " ")
+		e := scenarioEndpoint{Key: k, Method: method, Path: uri, Params: make([]scenarioParam, 0)}
+		if param.Matched[k] {
+			path, names, ok := openapi.PathTemplate(uri)
+			if !ok {
+				path = ""
+			}
+			e.Path = path
+			for i, name := range names {
+				var values map[string]int
+				if i < len(param.Path[k]) {
+					values = param.Path[k][i]
+				}
+				e.Params = append(e.Params, scenarioParam{Name: name, Values: topValues(values, param.Count[k], maxParamValues)})
+			}
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints
+}
+
+// iterationWeights counts the observed counts of a group and returns pairs of the count and its frequency sorted by the count.
+// It returns the count of the default choice of the group if nothing is observed:
+// a single iteration of a repetition, taking an optional, or the first alternative.
+func iterationWeights(n pattern.Node, iterations []int) [][2]int {
+	if len(iterations) == 0 {
+		if n.Kind() == pattern.Alternation {
+			return [][2]int{{0, 1}}
+		}
+		return [][2]int{{1, 1}}
+	}
+	freq := map[int]int{}
+	for _, n := range iterations {
+		freq[n]++
+	}
+	weights := make([][2]int, 0, len(freq))
+	for n, c := range freq {
+		weights = append(weights, [2]int{n, c})
+	}
+	slices.SortFunc(weights, func(a, b [2]int) int { return cmp.Compare(a[0], b[0]) })
+	return weights
+}
+
+// jsString returns the string literal of JavaScript
+func jsString(s string) string {
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func printScenarioK6(cmd *cobra.Command, scenarioStructs []internal.ScenarioStruct, param *internal.Param) error {
+	cmd.Println("// Generated by stool scenario --format k6")
+	cmd.Println("// Run with: k6 run -e BASE_URL=http://localhost:8080 script.js")
+	cmd.Println("import http from \"k6/http\";")
+	cmd.Println()
+	cmd.Println("const BASE_URL = __ENV.BASE_URL || \"http://localhost:8080\";")
+	cmd.Println()
+
+	// endpoints
+	cmd.Println("const endpoints = {")
+	for _, e := range newScenarioEndpoints(scenarioStructs, param) {
+		if e.Path == "" {
+			cmd.Printf("  // %s cannot be converted into a path, so it is not requested\n", e.Key)
+			cmd.Printf("  %s: { method: %s, path: null, params: {} },\n", jsString(e.Key), jsString(e.Method))
+			continue
+		}
+		cmd.Printf("  %s: {\n", jsString(e.Key))
+		cmd.Printf("    method: %s,\n", jsString(e.Method))
+		cmd.Printf("    path: %s,\n", jsString(e.Path))
+		if len(e.Params) == 0 {
+			cmd.Println("    params: {},")
+			cmd.Println("  },")
+			continue
+		}
+		cmd.Println("    params: {")
+		for _, p := range e.Params {
+			values := make([]string, 0, len(p.Values))
+			for _, v := range p.Values {
+				values = append(values, fmt.Sprintf("[%s, %d]", jsString(v.Value), v.Count))
+			}
+			cmd.Printf("      %s: [%s],\n", jsString(p.Name), strings.Join(values, ", "))
+		}
+		cmd.Println("    },")
+		cmd.Println("  },")
+	}
+	cmd.Println("};")
+	cmd.Println()
+
+	// options
+	cmd.Println("export const options = {")
+	cmd.Println("  scenarios: {")
+	for i, s := range scenarioStructs {
+		cmd.Printf("    scenario%d: {\n", i+1)
+		cmd.Println("      executor: \"shared-iterations\",")
+		cmd.Printf("      exec: \"scenario%d\",\n", i+1)
+		cmd.Printf("      vus: %d,\n", min(s.Count, maxK6VUs))
+		cmd.Printf("      iterations: %d,\n", s.Count)
+		cmd.Printf("      startTime: \"%dms\",\n", s.FirstReq)
+		cmd.Println("    },")
+	}
+	cmd.Println("  },")
+	cmd.Println("};")
+	cmd.Println()
+
+	// helpers
+	cmd.Println(`// pick chooses a value from pairs of a value and its weight
+function pick(weights) {
+  let r = Math.random() * weights.reduce((sum, w) => sum + w[1], 0);
+  for (const [value, weight] of weights) {
+    r -= weight;
+    if (r < 0) {
+      return value;
+    }
+  }
+  return weights[weights.length - 1][0];
+}
+
+function request(key) {
+  const e = endpoints[key];
+  if (e.path === null) {
+    return;
+  }
+  const path = e.path.replace(/{([^}]+)}/g, (_, name) => pick(e.params[name]));
+  return http.request(e.method, BASE_URL + path, null, { tags: { name: key } });
+}`)
+
+	// scenarios
+	for i, s := range scenarioStructs {
+		cmd.Println()
+		cmd.Printf("// Scenario #%d (count: %d, req: %d - %d [ms])\n", i+1, s.Count, s.FirstReq, s.LastReq)
+		cmd.Printf("// %s\n", s.Pattern.String(true))
+		cmd.Printf("export function scenario%d() {\n", i+1)
+		iterations := s.Iterations()
+		group := 0
+		printK6Nodes(cmd, s.Pattern.Children(), iterations, &group, 1)
+		cmd.Println("}")
+	}
+	return nil
+}
+
+// printK6Nodes prints the requests of the nodes. Repeat groups become loops whose iteration counts are chosen from the observed ones,
+// and optionals and alternations become branches chosen by the observed frequencies.
+// group is the index of the next group in the order of pattern.Node.Groups.
+func printK6Nodes(cmd *cobra.Command, nodes []pattern.Node, iterations [][]int, group *int, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, n := range nodes {
+		if n.IsLeaf() {
+			cmd.Printf("%srequest(%s);\n", indent, jsString(n.Value()))
+			continue
+		}
+		weights := make([]string, 0)
+		for _, w := range iterationWeights(n, iterations[*group]) {
+			weights = append(weights, fmt.Sprintf("[%d, %d]", w[0], w[1]))
+		}
+		*group++
+		switch n.Kind() {
+		case pattern.Optional:
+			cmd.Printf("%sif (pick([%s]) === 1) {\n", indent, strings.Join(weights, ", "))
+			printK6Nodes(cmd, n.Children(), iterations, group, depth+1)
+		case pattern.Alternation:
+			cmd.Printf("%sswitch (pick([%s])) {\n", indent, strings.Join(weights, ", "))
+			for i, alternative := range n.Children() {
+				cmd.Printf("%s  case %d:\n", indent, i)
+				printK6Nodes(cmd, alternative.Children(), iterations, group, depth+2)
+				cmd.Printf("%s    break;\n", indent)
+			}
+		default:
+			v := fmt.Sprintf("i%d", depth)
+			cmd.Printf("%sfor (let %s = pick([%s]); %s > 0; %s--) {\n", indent, v, strings.Join(weights, ", "), v, v)
+			printK6Nodes(cmd, n.Children(), iterations, group, depth+1)
+		}
+		cmd.Printf("%s}\n", indent)
+	}
+}
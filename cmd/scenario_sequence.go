@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/pattern"
+	"github.com/spf13/cobra"
+)
+
+// sequenceDialect is the syntax of a sequence diagram. Both Mermaid and PlantUML share the keywords of the groups
+// like "loop", "opt", "alt", "else" and "end".
+type sequenceDialect struct {
+	message   string // format of a request from the user to the server
+	note      string // format of a note over the user and the server
+	thinkTime string // format of a note of the think time before a request
+	escape    func(string) string
+}
+
+var mermaidSequence = sequenceDialect{
+	message:   "User->>Server: %s",
+	note:      "Note over User,Server: %s",
+	thinkTime: "Note left of User: %s",
+	escape:    mermaidText,
+}
+
+var plantUMLSequence = sequenceDialect{
+	message:   "User -> Server: %s",
+	note:      "note over User, Server: %s",
+	thinkTime: "note left of User: %s",
+	escape:    func(s string) string { return s },
+}
+
+// mermaidText escapes the characters which have special meanings in Mermaid with the entity codes
+func mermaidText(s string) string {
+	return strings.NewReplacer("#", "#35;", ";", "#59;").Replace(s)
+}
+
+// printScenarioMermaidSequence prints a Mermaid sequence diagram for each scenario in a fenced code block of Markdown
+func printScenarioMermaidSequence(cmd *cobra.Command, scenarioStructs []internal.ScenarioStruct, _ bool) error {
+	for i, s := range scenarioStructs {
+		if i > 0 {
+			cmd.Println()
+		}
+		cmd.Println("```mermaid")
+		cmd.Println("---")
+		cmd.Printf("title: %s\n", jsString(fmt.Sprintf("Scenario #%d", i+1)))
+		cmd.Println("---")
+		cmd.Println("sequenceDiagram")
+		printSequence(cmd, mermaidSequence, s)
+		cmd.Println("```")
+	}
+	return nil
+}
+
+// printScenarioPlantUML prints a PlantUML sequence diagram for each scenario
+func printScenarioPlantUML(cmd *cobra.Command, scenarioStructs []internal.ScenarioStruct, _ bool) error {
+	for i, s := range scenarioStructs {
+		if i > 0 {
+			cmd.Println()
+		}
+		cmd.Println("@startuml")
+		cmd.Printf("title Scenario #%d\n", i+1)
+		printSequence(cmd, plantUMLSequence, s)
+		cmd.Println("@enduml")
+	}
+	return nil
+}
+
+func printSequence(cmd *cobra.Command, d sequenceDialect, s internal.ScenarioStruct) {
+	timing := s.Timing()
+	cmd.Println("  actor User")
+	cmd.Println("  participant Server")
+	cmd.Printf("  %s\n", fmt.Sprintf(d.note, d.escape(scenarioSummary(s, timing))))
+	group := 0
+	printSequenceNodes(cmd, d, s.Pattern.Children(), s.Iterations(), timing, &group, 0, 1)
+}
+
+// printSequenceNodes prints the requests of the nodes. Repeat groups become "loop" blocks, optionals become "opt" blocks,
+// and alternations become "alt" blocks, which are labeled with the observed counts.
+// group is the index of the next group in the order of pattern.Node.Groups, and base is the index of the first leaf of the nodes.
+func printSequenceNodes(cmd *cobra.Command, d sequenceDialect, nodes []pattern.Node, iterations [][]int, timing internal.ScenarioTiming, group *int, base int, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, n := range nodes {
+		if n.IsLeaf() {
+			if stats, ok := timing.ThinkTimeBefore[base]; ok {
+				cmd.Printf("%s%s\n", indent, fmt.Sprintf(d.thinkTime, d.escape(fmt.Sprintf("think time p50 %s / p90 %s", stats.P50, stats.P90))))
+			}
+			cmd.Printf("%s%s\n", indent, fmt.Sprintf(d.message, d.escape(n.Value())))
+			base++
+			continue
+		}
+
+		counts := iterations[*group]
+		*group++
+		switch n.Kind() {
+		case pattern.Optional:
+			cmd.Printf("%sopt %s\n", indent, d.escape(optionalLabel(counts)))
+			printSequenceNodes(cmd, d, n.Children(), iterations, timing, group, base, depth+1)
+		case pattern.Alternation:
+			offset := base
+			for i, alternative := range n.Children() {
+				keyword := "alt"
+				if i > 0 {
+					keyword = "else"
+				}
+				cmd.Printf("%s%s %s\n", indent, keyword, d.escape(alternativeLabel(counts, i)))
+				printSequenceNodes(cmd, d, alternative.Children(), iterations, timing, group, offset, depth+1)
+				offset += alternative.Leaves()
+			}
+		default:
+			cmd.Printf("%sloop %s\n", indent, d.escape(loopLabel(counts)))
+			printSequenceNodes(cmd, d, n.Children(), iterations, timing, group, base, depth+1)
+		}
+		cmd.Printf("%send\n", indent)
+		base += n.Leaves()
+	}
+}
+
+// loopLabel returns the range and the mean of the observed iteration counts like "1-3 times (mean 1.8)"
+func loopLabel(counts []int) string {
+	if len(counts) == 0 {
+		return "repeat"
+	}
+	sum := 0
+	for _, c := range counts {
+		sum += c
+	}
+	mean := float64(sum) / float64(len(counts))
+	lo, hi := slices.Min(counts), slices.Max(counts)
+	if lo == hi {
+		return fmt.Sprintf("%d times", lo)
+	}
+	return fmt.Sprintf("%d-%d times (mean %.1f)", lo, hi, mean)
+}
+
+// optionalLabel returns the ratio of the sessions taking the optional like "taken 80% (4/5)"
+func optionalLabel(counts []int) string {
+	if len(counts) == 0 {
+		return "optional"
+	}
+	taken := 0
+	for _, c := range counts {
+		taken += c
+	}
+	return fmt.Sprintf("taken %d%% (%d/%d)", taken*100/len(counts), taken, len(counts))
+}
+
+// alternativeLabel returns the ratio of the sessions taking the i-th alternative like "75% (3/4)"
+func alternativeLabel(counts []int, i int) string {
+	if len(counts) == 0 {
+		return fmt.Sprintf("alternative %d", i+1)
+	}
+	taken := 0
+	for _, c := range counts {
+		if c == i {
+			taken++
+		}
+	}
+	return fmt.Sprintf("%d%% (%d/%d)", taken*100/len(counts), taken, len(counts))
+}
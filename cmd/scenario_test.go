@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/haijima/stool/internal"
+	"github.com/haijima/stool/internal/pattern"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -55,6 +59,8 @@ func Test_ScenarioCmd_RunE(t *testing.T) {
 	assert.Contains(t, stdout.String(), "end")
 	assert.Contains(t, stdout.String(), "\"POST /initialize\"")
 	assert.Contains(t, stdout.String(), "\"GET /\"")
+	assert.Contains(t, stdout.String(), "duration: p50 1s / p90 1s, requests: p50 2 / max 2")
+	assert.Contains(t, stdout.String(), "label=\"p50 1s / p90 1s\"")
 }
 
 func Test_ScenarioCmd_RunE_format_csv(t *testing.T) {
@@ -75,7 +81,156 @@ func Test_ScenarioCmd_RunE_format_csv(t *testing.T) {
 	err := cmd.RunE(cmd, []string{})
 
 	assert.NoError(t, err)
-	assert.Equal(t, "first call[s],last call[s],count,scenario node\n0,0,1,POST /initialize\n1000,2000,1,(GET /)*\n", stdout.String())
+	assert.Equal(t, "first call[s],last call[s],count,scenario node,duration p50[ms],duration p90[ms],requests,requests p50,requests max,think time p50/p90 per edge[ms]\n0,0,1,POST /initialize,0,0,1,1,1,\n1000,2000,1,(GET /)*,1000,1000,2,2,2,0->0:1000/1000\n", stdout.String())
+}
+
+func Test_ScenarioCmd_RunE_format_json(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "json")
+	_, _ = fs.Create(fileName)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:POST /initialize HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET / HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\ntime:01/Jan/2023:12:00:03 +0900\treq:GET / HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	var report ScenarioReport
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.Equal(t, 2, len(report.Scenarios))
+	assert.Equal(t, ScenarioStructReport{
+		Pattern:  "(GET /)*",
+		Count:    1,
+		Members:  1,
+		FirstReq: 1000,
+		LastReq:  2000,
+		Tree: PatternReport{Degree: 1, Leaves: 1, Children: []PatternReport{
+			{Repeat: true, Degree: 1, Leaves: 1, Children: []PatternReport{{Endpoint: "GET /", Leaves: 1}}},
+		}},
+		Timing: TimingReport{
+			Duration: DurationReport{Count: 1, Mean: 1000, P50: 1000, P90: 1000, P99: 1000, Max: 1000},
+			Requests: RequestsReport{Total: 2, Mean: 2, P50: 2, P90: 2, Max: 2},
+			ThinkTimes: []ThinkTimeReport{
+				{From: 0, To: 0, DurationReport: DurationReport{Count: 1, Mean: 1000, P50: 1000, P90: 1000, P99: 1000, Max: 1000}},
+			},
+		},
+	}, report.Scenarios[1])
+
+	n, err := pattern.Parse(report.Scenarios[1].Pattern)
+	assert.NoError(t, err)
+	assert.Equal(t, report.Scenarios[1].Pattern, n.String(true))
+}
+
+func Test_ScenarioCmd_RunE_format_json_noEscape(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "json")
+	v.Set("drop_start_near_end_ratio", 0)
+	_, _ = fs.Create(fileName)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), `"pattern": "POST /login -> GET /items"`)
+}
+
+func Test_ScenarioCmd_RunE_format_k6(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "k6")
+	v.Set("matching_groups", []string{"^/users/(?P<id>[0-9]+)$"})
+	_, _ = fs.Create(fileName)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /users/12 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:03 +0900\treq:GET /users/13 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:04 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\ntime:01/Jan/2023:12:00:05 +0900\treq:GET /users/12 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "import http from \"k6/http\";")
+	assert.Contains(t, stdout.String(), "    path: \"/users/{id}\",\n    params: {\n      \"id\": [[\"12\", 2], [\"13\", 1]],\n    },\n")
+	assert.Contains(t, stdout.String(), "      exec: \"scenario1\",\n      vus: 2,\n      iterations: 2,\n      startTime: \"0ms\",\n")
+	assert.Contains(t, stdout.String(), "export function scenario1() {\n  request(\"POST /login\");\n  for (let i1 = pick([[1, 1], [2, 1]]); i1 > 0; i1--) {\n    request(\"GET ^/users/(?P<id>[0-9]+)$\");\n  }\n}\n")
+}
+
+func Test_ScenarioCmd_RunE_format_go(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "go")
+	v.Set("out_dir", "bench")
+	v.Set("package", "bench")
+	v.Set("matching_groups", []string{"^/users/(?P<id>[0-9]+)$"})
+	_, _ = fs.Create(fileName)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /users/12 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:03 +0900\treq:GET /users/13 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:04 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\ntime:01/Jan/2023:12:00:05 +0900\treq:GET /users/12 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n"), 0777)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	src, err := afero.ReadFile(fs, "bench/scenario.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), "package bench\n")
+	assert.Contains(t, string(src), "\t\t\t\"id\": {{\"12\", 2}, {\"13\", 1}},\n")
+	assert.Contains(t, string(src), "func (c *Client) Scenario1(ctx context.Context) error {\n\tif err := c.request(ctx, \"POST /login\"); err != nil {\n\t\treturn err\n\t}\n\tfor i1 := pick([]weighted[int]{{1, 1}, {2, 1}}); i1 > 0; i1-- {\n")
+	assert.Contains(t, string(src), "\t{(*Client).Scenario1, 2},\n")
+	assert.Contains(t, string(src), "\tif sum <= 0 {\n\t\tvar zero T\n\t\treturn zero\n\t}\n")
+	exists, _ := afero.Exists(fs, "bench/scenario_test.go")
+	assert.True(t, exists)
+}
+
+func Test_ScenarioCmd_RunE_format_go_compile(t *testing.T) {
+	goCmd, err := exec.LookPath("go")
+	if err != nil || testing.Short() {
+		t.Skip("go command is required to compile the generated package")
+	}
+	p := internal.NewScenarioProfiler()
+	v, _ := createViperAndFs()
+	fs := afero.NewOsFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "access.log")
+	v.Set("file", fileName)
+	v.Set("format", "go")
+	v.Set("out_dir", filepath.Join(dir, "bench"))
+	v.Set("matching_groups", []string{"^/users/(?P<id>[0-9]+)$"})
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /users/12 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:03 +0900\treq:GET /users/13 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:04 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\ntime:01/Jan/2023:12:00:05 +0900\treq:GET /users/12 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n"), 0644)
+	_ = afero.WriteFile(fs, filepath.Join(dir, "go.mod"), []byte("module example.com/bench\n\ngo 1.21\n"), 0644)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err = cmd.RunE(cmd, []string{})
+	assert.NoError(t, err)
+
+	test := exec.Command(goCmd, "test", "./...")
+	test.Dir = dir
+	out, err := test.CombinedOutput()
+	assert.NoError(t, err, string(out))
 }
 
 func Test_ScenarioCmd_RunE_palette(t *testing.T) {
@@ -98,6 +253,292 @@ func Test_ScenarioCmd_RunE_palette(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_ScenarioCmd_RunE_format_mermaid(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "mermaid")
+	v.Set("similarity", 0.6)
+	v.Set("drop_start_near_end_ratio", 0)
+	_, _ = fs.Create(fileName)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:01 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:03 +0900\treq:POST /cart HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:04 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\ntime:01/Jan/2023:12:00:06 +0900\treq:POST /cart HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n"), 0644)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "---\ntitle: stool scenario\n---\nflowchart LR\n"+
+		"\tsubgraph s0 [\"Scenario #35;1  (count: 2, members: 2, req: 0 - 6000 [ms], duration: p50 2s / p90 3s, requests: p50 2 / max 3)\"]\n"+
+		"\t\tdirection LR\n"+
+		"\t\ts0_start([start])\n"+
+		"\t\ts0_end([end])\n"+
+		"\t\ts0_0[\"POST /login\"]\n"+
+		"\t\ts0_1[\"GET /items\"]:::optional\n"+
+		"\t\ts0_2[\"POST /cart\"]\n"+
+		"\t\ts0_start --> s0_0\n"+
+		"\t\ts0_0 -->|\"p50 1s / p90 1s\"| s0_1\n"+
+		"\t\ts0_0 -->|\"p50 2s / p90 2s\"| s0_2\n"+
+		"\t\ts0_1 -->|\"p50 2s / p90 2s\"| s0_2\n"+
+		"\t\ts0_2 --> s0_end\n"+
+		"\tend\n"+
+		"\tlinkStyle 0,1,2,3,4 stroke-width:11.0px\n"+
+		"\tclassDef optional stroke-dasharray: 5 5\n", stdout.String())
+}
+
+func Test_mermaidLabel(t *testing.T) {
+	assert.Equal(t, `"GET /search#quot;q#quot;#35;top"`, mermaidLabel(`GET /search"q"#top`))
+}
+
+func Test_ScenarioCmd_RunE_incompleteSessions(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "csv")
+	v.Set("drop_start_near_end", "1s")
+	v.Set("drop_active_at_end", "1s")
+	_, _ = fs.Create(fileName)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\ntime:01/Jan/2023:12:00:01 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\ntime:01/Jan/2023:12:00:05 +0900\treq:GET / HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\ntime:01/Jan/2023:12:00:05 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635C26725F02560303\tuidgot:-\n"), 0644)
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "first call[s],last call[s],count,scenario node,duration p50[ms],duration p90[ms],requests,requests p50,requests max,think time p50/p90 per edge[ms]\n0,0,1,POST /login,0,0,1,1,1,\n", stdout.String())
+	assert.Equal(t, "Dropped 1 sessions that start within 1s or 5% of the period before the end of the log\nDropped 1 sessions that are active within 1s before the end of the log\n", stderr.String())
+}
+
+func Test_ScenarioCmd_RunE_session(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "csv")
+	v.Set("idle_timeout", "30s")
+	v.Set("uidgot_session", true)
+	_, _ = fs.Create(fileName)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:10 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\ntime:01/Jan/2023:12:01:00 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\ntime:01/Jan/2023:12:02:00 +0900\treq:GET / HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635C26725F02560303\tuidgot:-\n"), 0644)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "first call[s],last call[s],count,scenario node,duration p50[ms],duration p90[ms],requests,requests p50,requests max,think time p50/p90 per edge[ms]\n0,60000,2,GET /items,0,0,2,1,1,\n10000,10000,1,POST /login,0,0,1,1,1,\n", stdout.String())
+}
+
+func Test_ScenarioCmd_RunE_noDrop(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "csv")
+	v.Set("idle_timeout", "30s")
+	v.Set("uidgot_session", true)
+	v.Set("drop_start_near_end_ratio", 0)
+	_, _ = fs.Create(fileName)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\ntime:01/Jan/2023:12:00:10 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\ntime:01/Jan/2023:12:01:00 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\ntime:01/Jan/2023:12:02:00 +0900\treq:GET / HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635C26725F02560303\tuidgot:-\n"), 0644)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "first call[s],last call[s],count,scenario node,duration p50[ms],duration p90[ms],requests,requests p50,requests max,think time p50/p90 per edge[ms]\n0,60000,2,GET /items,0,0,2,1,1,\n10000,10000,1,POST /login,0,0,1,1,1,\n120000,120000,1,GET /,0,0,1,1,1,\n", stdout.String())
+}
+
+func Test_ScenarioCmd_RunE_similarity(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "json")
+	v.Set("similarity", 0.6)
+	_, _ = fs.Create(fileName)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n"+
+		"time:01/Jan/2023:12:00:01 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\n"+
+		"time:01/Jan/2023:12:00:02 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635C26725F02560303\tuidgot:-\n"+
+		"time:01/Jan/2023:12:00:03 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"+
+		"time:01/Jan/2023:12:00:04 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n"+
+		"time:01/Jan/2023:12:00:05 +0900\treq:POST /cart HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"+
+		"time:01/Jan/2023:12:00:06 +0900\treq:POST /cart HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n"+
+		"time:01/Jan/2023:12:00:07 +0900\treq:POST /cart HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635C26725F02560303\n"), 0644)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	var report ScenarioReport
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.Equal(t, 1, len(report.Scenarios))
+	assert.Equal(t, "POST /login -> GET /items? -> POST /cart", report.Scenarios[0].Pattern)
+	assert.Equal(t, 3, report.Scenarios[0].Count)
+	assert.Equal(t, 2, report.Scenarios[0].Members)
+	assert.Equal(t, PatternReport{Index: 1, Optional: true, Degree: 1, Leaves: 1, Children: []PatternReport{{Endpoint: "GET /items", Index: 1, Leaves: 1}}}, report.Scenarios[0].Tree.Children[1])
+	assert.Equal(t, 3, report.Scenarios[0].Timing.Duration.Count)
+}
+
+func Test_ScenarioCmd_RunE_format_sequence(t *testing.T) {
+	input := "time:01/Jan/2023:12:00:00 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n" +
+		"time:01/Jan/2023:12:00:03 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "mermaid-sequence",
+			want: "```mermaid\n---\ntitle: \"Scenario #1\"\n---\nsequenceDiagram\n  actor User\n  participant Server\n" +
+				"  Note over User,Server: count: 1, req: 0 - 3000 [ms], duration: p50 3s / p90 3s, requests: p50 3 / max 3\n" +
+				"  User->>Server: POST /login\n  loop 2 times\n    Note left of User: think time p50 1s / p90 2s\n    User->>Server: GET /items\n  end\n```\n",
+		},
+		{
+			format: "plantuml",
+			want: "@startuml\ntitle Scenario #1\n  actor User\n  participant Server\n" +
+				"  note over User, Server: count: 1, req: 0 - 3000 [ms], duration: p50 3s / p90 3s, requests: p50 3 / max 3\n" +
+				"  User -> Server: POST /login\n  loop 2 times\n    note left of User: think time p50 1s / p90 2s\n    User -> Server: GET /items\n  end\n@enduml\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			p := internal.NewScenarioProfiler()
+			v, fs := createViperAndFs()
+			cmd := NewScenarioCmd(p, v, fs)
+
+			fileName := "./access.log"
+			v.Set("file", fileName)
+			v.Set("format", tt.format)
+			_, _ = fs.Create(fileName)
+			_ = afero.WriteFile(fs, fileName, []byte(input), 0644)
+
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+
+			_ = v.BindPFlags(cmd.Flags())
+			err := cmd.RunE(cmd, []string{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, stdout.String())
+		})
+	}
+}
+
+func Test_sequenceLabels(t *testing.T) {
+	assert.Equal(t, "repeat", loopLabel(nil))
+	assert.Equal(t, "2 times", loopLabel([]int{2, 2}))
+	assert.Equal(t, "1-3 times (mean 1.8)", loopLabel([]int{1, 3, 2, 1}))
+	assert.Equal(t, "optional", optionalLabel(nil))
+	assert.Equal(t, "taken 75% (3/4)", optionalLabel([]int{1, 0, 1, 1}))
+	assert.Equal(t, "alternative 2", alternativeLabel(nil, 1))
+	assert.Equal(t, "25% (1/4)", alternativeLabel([]int{0, 1, 0, 0}, 1))
+	assert.Equal(t, "GET /a#59;b#35;c", mermaidText("GET /a;b#c"))
+}
+
+func Test_patternToNodeAndEdge(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		edges    []edge
+		first    []int
+		last     []int
+		optional map[int]bool
+	}{
+		{name: "sequence", pattern: "A -> B", edges: []edge{{0, 1}}, first: []int{0}, last: []int{1}, optional: map[int]bool{}},
+		{name: "repeat at root", pattern: "(A)*", edges: []edge{{0, 0}}, first: []int{0}, last: []int{0}, optional: map[int]bool{}},
+		{name: "repeat", pattern: "(A -> B)* -> C", edges: []edge{{0, 1}, {1, 0}, {1, 2}}, first: []int{0}, last: []int{2}, optional: map[int]bool{}},
+		{name: "optional", pattern: "A -> B? -> C", edges: []edge{{0, 1}, {0, 2}, {1, 2}}, first: []int{0}, last: []int{2}, optional: map[int]bool{1: true}},
+		{name: "optional at the ends", pattern: "A? -> B -> C?", edges: []edge{{0, 1}, {1, 2}}, first: []int{0, 1}, last: []int{1, 2}, optional: map[int]bool{0: true, 2: true}},
+		{name: "alternation", pattern: "A -> (B | C -> D) -> E", edges: []edge{{0, 1}, {2, 3}, {0, 2}, {1, 4}, {3, 4}}, first: []int{0}, last: []int{4}, optional: map[int]bool{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := pattern.Parse(tt.pattern)
+			assert.NoError(t, err)
+			nodes := map[int]string{}
+			optional := map[int]bool{}
+			edges := make([]edge, 0)
+
+			first, last, _ := patternToNodeAndEdge(*p, nodes, optional, &edges, 0, true)
+
+			assert.Equal(t, p.Leaves(), len(nodes))
+			assert.ElementsMatch(t, tt.edges, edges)
+			assert.Equal(t, tt.first, first)
+			assert.Equal(t, tt.last, last)
+			assert.Equal(t, tt.optional, optional)
+		})
+	}
+}
+
+func Test_ScenarioCmd_RunE_format_k6_choices(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "k6")
+	v.Set("similarity", 0.6)
+	_, _ = fs.Create(fileName)
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:00 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n"+
+		"time:01/Jan/2023:12:00:01 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\n"+
+		"time:01/Jan/2023:12:00:02 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635C26725F02560303\tuidgot:-\n"+
+		"time:01/Jan/2023:12:00:03 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"+
+		"time:01/Jan/2023:12:00:04 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n"+
+		"time:01/Jan/2023:12:00:05 +0900\treq:GET /sale HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635C26725F02560303\n"+
+		"time:01/Jan/2023:12:00:06 +0900\treq:POST /cart HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n"+
+		"time:01/Jan/2023:12:00:07 +0900\treq:POST /cart HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n"+
+		"time:01/Jan/2023:12:00:08 +0900\treq:POST /cart HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635C26725F02560303\n"), 0644)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "// POST /login -> (GET /items | GET /sale) -> POST /cart\n")
+	assert.Contains(t, stdout.String(), "  switch (pick([[0, 2], [1, 1]])) {\n    case 0:\n      request(\"GET /items\");\n      break;\n    case 1:\n      request(\"GET /sale\");\n      break;\n  }\n")
+}
+
+func Test_ScenarioCmd_RunE_invalidSimilarity(t *testing.T) {
+	p := internal.NewScenarioProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewScenarioCmd(p, v, fs)
+
+	v.Set("similarity", 1.5)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.EqualError(t, err, "similarity flag should be between 0 and 1. but: 1.5")
+}
+
 func BenchmarkScenarioCommand_RunE(b *testing.B) {
 	p := internal.NewScenarioProfiler()
 	v := viper.New()
@@ -29,6 +29,8 @@ func NewTransitionCmd(p *internal.TransitionProfiler, v *viper.Viper, fs afero.F
 	}
 
 	transitionCmd.Flags().String("format", "dot", "The output format {dot|mermaid|csv}")
+	transitionCmd.Flags().Duration("idle_timeout", 0, "Start a new session when a user is idle longer than the timeout. 0 means no timeout")
+	transitionCmd.Flags().Bool("uidgot_session", true, "Start a session for a user who is first seen via uidgot")
 
 	return transitionCmd
 }
@@ -39,6 +41,10 @@ func runTransition(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.
 	labels := v.GetStringMapString("log_labels")
 	filter := v.GetString("filter")
 	format := v.GetString("format")
+	sessionOpt := internal.SessionOption{
+		IdleTimeout:   v.GetDuration("idle_timeout"),
+		StartOnUidGot: v.GetBool("uidgot_session"),
+	}
 
 	f, err := cobrax.OpenOrStdIn(v.GetString("file"), fs, cobrax.WithStdin(cmd.InOrStdin()))
 	if err != nil {
@@ -55,7 +61,7 @@ func runTransition(cmd *cobra.Command, v *viper.Viper, fs afero.Fs, p *internal.
 		return err
 	}
 
-	result, err := p.Profile(logReader)
+	result, err := p.Profile(logReader, internal.TransitionOption{SessionOption: sessionOpt})
 	if err != nil {
 		return err
 	}
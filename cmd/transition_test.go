@@ -90,6 +90,50 @@ func Test_TransitionCmd_RunE_format_csv(t *testing.T) {
 	assert.Equal(t, ",,GET /,POST /initialize\n,0,1,1\nGET /,1,1,0\nPOST /initialize,1,0,0\n", stdout.String())
 }
 
+func Test_TransitionCmd_RunE_uidgot_only(t *testing.T) {
+	p := internal.NewTransitionProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewTransitionCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "csv")
+	_, _ = fs.Create(fileName)
+	// the user's cookie predates the log, so the uid is never set in it
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET / HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\ntime:01/Jan/2023:12:00:02 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, ",,GET /,POST /login\n,0,1,0\nGET /,0,0,1\nPOST /login,1,0,0\n", stdout.String())
+}
+
+func Test_TransitionCmd_RunE_uidset_again(t *testing.T) {
+	p := internal.NewTransitionProfiler()
+	v, fs := createViperAndFs()
+	cmd := NewTransitionCmd(p, v, fs)
+
+	fileName := "./access.log"
+	v.Set("file", fileName)
+	v.Set("format", "csv")
+	_, _ = fs.Create(fileName)
+	// setting the uid again, e.g. on login, ends the previous session and starts a new one
+	_ = afero.WriteFile(fs, fileName, []byte("time:01/Jan/2023:12:00:01 +0900\treq:GET / HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\ntime:01/Jan/2023:12:00:02 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\n"), 0777)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+
+	_ = v.BindPFlags(cmd.Flags())
+	err := cmd.RunE(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, ",,GET /,POST /login\n,0,1,1\nGET /,1,0,0\nPOST /login,1,0,0\n", stdout.String())
+}
+
 func Test_TransitionCmd_RunE_format_mermaid(t *testing.T) {
 	p := internal.NewTransitionProfiler()
 	v, fs := createViperAndFs()
@@ -111,21 +111,27 @@ func resultToRows(result *internal.Trend, humanized bool) []table.Row {
 	for _, endpoint := range result.Endpoints() {
 		methodAndUri := strings.SplitN(endpoint, " ", 2) // split into Method and Uri
 		row := table.Row{methodAndUri[0], methodAndUri[1]}
-		for i, count := range result.Counts(endpoint) {
-			s := strconv.Itoa(count)
-			if humanized {
-				s = humanize.Comma(int64(count))
-			}
-			if i > 0 && count*2 > result.Counts(endpoint)[i-1]*3 {
-				s = color.GreenString(s)
-			} else if count == 0 {
-				s = color.HiBlackString(s)
-			} else if i > 0 && count*3 < result.Counts(endpoint)[i-1]*2 {
-				s = color.RedString(s)
-			}
-			row = append(row, s)
+		for i := range result.Counts(endpoint) {
+			row = append(row, trendCell(result.Counts(endpoint), i, humanized))
 		}
 		rows = append(rows, row)
 	}
 	return rows
 }
+
+// trendCell formats the i-th count colored by the change from the previous one
+func trendCell(counts []int, i int, humanized bool) string {
+	count := counts[i]
+	s := strconv.Itoa(count)
+	if humanized {
+		s = humanize.Comma(int64(count))
+	}
+	if i > 0 && count*2 > counts[i-1]*3 {
+		s = color.GreenString(s)
+	} else if count == 0 {
+		s = color.HiBlackString(s)
+	} else if i > 0 && count*3 < counts[i-1]*2 {
+		s = color.RedString(s)
+	}
+	return s
+}
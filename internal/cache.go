@@ -0,0 +1,247 @@
+package internal
+
+import (
+	"container/list"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+	"golang.org/x/exp/maps"
+)
+
+type CacheProfiler struct {
+}
+
+func NewCacheProfiler() *CacheProfiler {
+	return &CacheProfiler{}
+}
+
+// CacheOption is the settings of the simulated caches
+type CacheOption struct {
+	TTLs        []time.Duration // TTLs of the caches keyed on the full URI
+	Capacities  []int           // capacities of the LRU caches keyed on the path parameter values
+	IgnoreQuery []string        // query keys removed from the cache key. "*" removes the whole query
+	Invalidate  bool            // whether a write request invalidates the cached responses of the same resource ID
+}
+
+// writeMethods are the methods that invalidate the cached responses
+var writeMethods = []string{"POST", "PUT", "PATCH", "DELETE"}
+
+// Profile replays GET requests through a TTL cache per TTL and an LRU cache per capacity of each endpoint.
+// The log entries are expected to be in chronological order.
+func (p *CacheProfiler) Profile(reader *log.LTSVReader, opt CacheOption) (*Cache, error) {
+	result := &Cache{
+		TTLs:          opt.TTLs,
+		Capacities:    opt.Capacities,
+		Requests:      make(map[string]int),
+		TTLHits:       make(map[string][]int),
+		LRUHits:       make(map[string][]int),
+		Invalidations: make(map[string]int),
+	}
+	caches := make(map[string]*endpointCache)
+	resources := make(map[string]map[cacheRef]struct{}) // resource ID -> cached entries of the resource
+
+	var entry log.LogEntry
+	for reader.Read() {
+		_, err := reader.Parse(&entry)
+		if err != nil {
+			if err == log.Filtered {
+				continue
+			}
+			return nil, err
+		}
+
+		_, path, query := log.ParseReq(entry.Req)
+		ids := resourceIDs(entry, path)
+		if slices.Contains(writeMethods, entry.Method) {
+			if opt.Invalidate {
+				for _, id := range ids {
+					for ref := range resources[id] {
+						if caches[ref.endpoint].remove(ref) {
+							result.Invalidations[ref.endpoint] += 1
+						}
+					}
+					delete(resources, id)
+				}
+			}
+			continue
+		} else if entry.Method != "GET" {
+			continue
+		}
+
+		key := entry.Key()
+		c, ok := caches[key]
+		if !ok {
+			c = newEndpointCache(opt.TTLs, opt.Capacities)
+			caches[key] = c
+			result.TTLHits[key] = make([]int, len(opt.TTLs))
+			result.LRUHits[key] = make([]int, len(opt.Capacities))
+		}
+		ref := cacheRef{endpoint: key, uri: canonicalURI(path, query, opt.IgnoreQuery), param: strings.Join(ids[1:], "&")}
+		if len(ids) == 1 { // no path parameter
+			ref.param = ref.uri
+		}
+		ttlHits, lruHits := c.get(ref, entry.Time)
+		for i, hit := range ttlHits {
+			if hit {
+				result.TTLHits[key][i] += 1
+			}
+		}
+		for i, hit := range lruHits {
+			if hit {
+				result.LRUHits[key][i] += 1
+			}
+		}
+		for _, id := range ids {
+			if _, ok := resources[id]; !ok {
+				resources[id] = map[cacheRef]struct{}{}
+			}
+			resources[id][ref] = struct{}{}
+		}
+		result.Requests[key] += 1
+	}
+
+	result.Endpoints = maps.Keys(result.Requests)
+	slices.Sort(result.Endpoints)
+	return result, nil
+}
+
+// Cache is the result of the cache simulation
+type Cache struct {
+	Endpoints     []string
+	TTLs          []time.Duration
+	Capacities    []int
+	Requests      map[string]int
+	TTLHits       map[string][]int // hits per TTL of each endpoint
+	LRUHits       map[string][]int // hits per capacity of each endpoint
+	Invalidations map[string]int   // cached entries removed by write requests
+}
+
+// resourceIDs returns the path and the path parameter values of the entry as resource IDs.
+// A value of a named capture group is prefixed with its name, and a value of an unnamed one is prefixed with the pattern
+// and its index, so that only the same kind of resources match.
+func resourceIDs(entry log.LogEntry, path string) []string {
+	ids := []string{path}
+	if entry.MatchedGroup == nil {
+		return ids
+	}
+	subMatches := entry.MatchedGroup.FindStringSubmatch(path)
+	names := entry.MatchedGroup.SubexpNames()
+	for i, v := range subMatches[1:] {
+		if names[i+1] != "" {
+			ids = append(ids, fmt.Sprintf("%s=%s", names[i+1], v))
+		} else {
+			ids = append(ids, fmt.Sprintf("%s#%d=%s", entry.MatchedGroup.String(), i+1, v))
+		}
+	}
+	return ids
+}
+
+// canonicalURI returns the path with the sorted query except the ignored keys
+func canonicalURI(path, query string, ignore []string) string {
+	if query == "" || slices.Contains(ignore, "*") {
+		return path
+	}
+	qs := slices.DeleteFunc(strings.Split(query, "&"), func(q string) bool {
+		k, _, _ := strings.Cut(q, "=")
+		return slices.Contains(ignore, k)
+	})
+	if len(qs) == 0 {
+		return path
+	}
+	slices.Sort(qs)
+	return path + "?" + strings.Join(qs, "&")
+}
+
+type cacheRef struct {
+	endpoint string
+	uri      string // key of the TTL caches
+	param    string // key of the LRU caches. the path parameter values, or uri if the endpoint has no path parameter
+}
+
+type endpointCache struct {
+	ttls    []time.Duration
+	expires []map[string]time.Time
+	lrus    []*lruCache
+}
+
+func newEndpointCache(ttls []time.Duration, capacities []int) *endpointCache {
+	c := &endpointCache{ttls: ttls}
+	for range ttls {
+		c.expires = append(c.expires, map[string]time.Time{})
+	}
+	for _, capacity := range capacities {
+		c.lrus = append(c.lrus, newLRUCache(capacity))
+	}
+	return c
+}
+
+// get looks up every cache and stores the response on a miss
+func (c *endpointCache) get(ref cacheRef, now time.Time) ([]bool, []bool) {
+	ttlHits := make([]bool, len(c.ttls))
+	for i, ttl := range c.ttls {
+		if exp, ok := c.expires[i][ref.uri]; ok && now.Before(exp) {
+			ttlHits[i] = true
+		} else {
+			c.expires[i][ref.uri] = now.Add(ttl)
+		}
+	}
+	lruHits := make([]bool, len(c.lrus))
+	for i, lru := range c.lrus {
+		lruHits[i] = lru.get(ref.param)
+	}
+	return ttlHits, lruHits
+}
+
+// remove removes the entries of ref from every cache and reports whether any entry is removed
+func (c *endpointCache) remove(ref cacheRef) bool {
+	removed := false
+	for _, expires := range c.expires {
+		if _, ok := expires[ref.uri]; ok {
+			delete(expires, ref.uri)
+			removed = true
+		}
+	}
+	for _, lru := range c.lrus {
+		removed = lru.remove(ref.param) || removed
+	}
+	return removed
+}
+
+// lruCache is a set of keys that evicts the least recently used key when it exceeds the capacity
+type lruCache struct {
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{capacity: capacity, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// get reports whether the key is cached, and stores it as the most recently used one
+func (c *lruCache) get(key string) bool {
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return true
+	}
+	c.items[key] = c.ll.PushFront(key)
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(string))
+	}
+	return false
+}
+
+func (c *lruCache) remove(key string) bool {
+	e, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.ll.Remove(e)
+	delete(c.items, key)
+	return true
+}
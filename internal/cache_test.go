@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheProfiler_Profile(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /users/1?b=2&a=1 HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:05 +0900\treq:GET /users/1?a=1&b=2&_=123 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // hit: same canonical URI
+		"time:01/Jan/2023:12:00:20 +0900\treq:GET /users/1?a=1&b=2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // hit only with TTL 1m
+		"time:01/Jan/2023:12:00:21 +0900\treq:GET /users/2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // miss: evicts 1 from LRU(1)
+		"time:01/Jan/2023:12:00:22 +0900\treq:GET /users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // hit only with LRU(2)
+		"time:01/Jan/2023:12:00:23 +0900\treq:PUT /users/1/name HTTP/2.0\tstatus:204\tuidset:-\tuidgot:uid=A\n" + // invalidates user 1
+		"time:01/Jan/2023:12:00:24 +0900\treq:GET /users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // miss
+		"time:01/Jan/2023:12:00:25 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:26 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n") // hit
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{MatchingGroups: []string{"^/users/(?P<id>[^/]+)$", "^/users/(?P<id>[^/]+)/name$"}})
+	require.NoError(t, err)
+
+	cache, err := NewCacheProfiler().Profile(logReader, CacheOption{
+		TTLs:        []time.Duration{10 * time.Second, time.Minute},
+		Capacities:  []int{1, 2},
+		IgnoreQuery: []string{"_"},
+		Invalidate:  true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET /items", "GET ^/users/(?P<id>[^/]+)$"}, cache.Endpoints)
+	assert.Equal(t, map[string]int{"GET /items": 2, "GET ^/users/(?P<id>[^/]+)$": 6}, cache.Requests)
+	assert.Equal(t, map[string][]int{"GET /items": {1, 1}, "GET ^/users/(?P<id>[^/]+)$": {1, 2}}, cache.TTLHits)
+	assert.Equal(t, map[string][]int{"GET /items": {1, 1}, "GET ^/users/(?P<id>[^/]+)$": {2, 3}}, cache.LRUHits)
+	assert.Equal(t, map[string]int{"GET ^/users/(?P<id>[^/]+)$": 2}, cache.Invalidations)
+}
+
+func Test_canonicalURI(t *testing.T) {
+	assert.Equal(t, "/users?a=1&b=2", canonicalURI("/users", "b=2&a=1", nil))
+	assert.Equal(t, "/users?b=2", canonicalURI("/users", "b=2&a=1", []string{"a"}))
+	assert.Equal(t, "/users", canonicalURI("/users", "a=1", []string{"a"}))
+	assert.Equal(t, "/users", canonicalURI("/users", "b=2&a=1", []string{"*"}))
+}
+
+func Test_lruCache(t *testing.T) {
+	c := newLRUCache(2)
+
+	assert.False(t, c.get("a"))
+	assert.False(t, c.get("b"))
+	assert.True(t, c.get("a"))
+	assert.False(t, c.get("c")) // evicts b
+	assert.False(t, c.get("b")) // evicts a
+	assert.True(t, c.get("c"))
+	assert.True(t, c.remove("c"))
+	assert.False(t, c.remove("c"))
+}
+
+func TestCacheProfiler_Profile_unnamedGroups(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /users/1 HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /items/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:02 +0900\treq:PUT /items/1 HTTP/2.0\tstatus:204\tuidset:-\tuidgot:uid=A\n" + // invalidates only item 1
+		"time:01/Jan/2023:12:00:03 +0900\treq:GET /users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // hit
+		"time:01/Jan/2023:12:00:04 +0900\treq:GET /items/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n") // miss
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{MatchingGroups: []string{"^/users/([^/]+)$", "^/items/([^/]+)$"}})
+	require.NoError(t, err)
+
+	cache, err := NewCacheProfiler().Profile(logReader, CacheOption{
+		TTLs:       []time.Duration{time.Minute},
+		Capacities: []int{10},
+		Invalidate: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]int{"GET ^/items/([^/]+)$": {0}, "GET ^/users/([^/]+)$": {1}}, cache.TTLHits)
+	assert.Equal(t, map[string][]int{"GET ^/items/([^/]+)$": {0}, "GET ^/users/([^/]+)$": {1}}, cache.LRUHits)
+	assert.Equal(t, map[string]int{"GET ^/items/([^/]+)$": 1}, cache.Invalidations)
+}
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/haijima/stool/internal/openapi"
+)
+
+type CoverageStatus int
+
+const (
+	Covered      CoverageStatus = iota // documented and exercised
+	NotExercised                       // documented but never requested
+	Undocumented                       // requested but not documented
+)
+
+func (s CoverageStatus) String() string {
+	switch s {
+	case Covered:
+		return "covered"
+	case NotExercised:
+		return "not exercised"
+	case Undocumented:
+		return "undocumented"
+	}
+	return ""
+}
+
+type Coverage struct {
+	Endpoints []*EndpointCoverage
+}
+
+type EndpointCoverage struct {
+	Method                string
+	Path                  string // path template of the spec, or the URI (or the matching group) of the request if undocumented
+	Status                CoverageStatus
+	Count                 int
+	UndocumentedQueryKeys map[string]int
+	UndeclaredStatuses    map[int]int
+}
+
+// CoverageMatchingGroups returns the matching groups to attribute requests to the paths of the spec.
+// The given matching groups follow them to group undocumented requests.
+func CoverageMatchingGroups(doc *openapi.Document, matchingGroups []string) []string {
+	return append(doc.MatchingGroups(), matchingGroups...)
+}
+
+// NewCoverage compares the observed requests with the operations of the spec.
+// param should be profiled with the matching groups returned by CoverageMatchingGroups.
+func NewCoverage(doc *openapi.Document, param *Param) *Coverage {
+	patternToPath := make(map[string]string, len(doc.Paths))
+	for path := range doc.Paths {
+		patternToPath[doc.PathRegexp(path)] = path
+	}
+
+	exercised := make(map[string]*EndpointCoverage)
+	coverage := &Coverage{Endpoints: make([]*EndpointCoverage, 0)}
+	for _, k := range param.Endpoints {
+		method, uri, _ := strings.Cut(k, " ")
+		path, ok := patternToPath[uri]
+		var op *openapi.Operation
+		if ok {
+			op = doc.Paths[path].Operations()[method]
+		}
+		if op == nil {
+			if !ok {
+				path = uri
+			}
+			coverage.Endpoints = append(coverage.Endpoints, &EndpointCoverage{Method: method, Path: path, Status: Undocumented, Count: param.Count[k]})
+			continue
+		}
+
+		ec := &EndpointCoverage{
+			Method:                method,
+			Path:                  path,
+			Status:                Covered,
+			Count:                 param.Count[k],
+			UndocumentedQueryKeys: make(map[string]int),
+			UndeclaredStatuses:    make(map[int]int),
+		}
+		declared := make(map[string]struct{})
+		for _, p := range append(slices.Clone(doc.Paths[path].Parameters), op.Parameters...) {
+			if p = doc.Parameter(p); p.In == "query" {
+				declared[p.Name] = struct{}{}
+			}
+		}
+		for qk, c := range param.QueryKey[k] {
+			if _, ok := declared[qk]; !ok {
+				ec.UndocumentedQueryKeys[qk] = c
+			}
+		}
+		for status, c := range param.Status[k] {
+			if !isDeclaredStatus(op, status) {
+				ec.UndeclaredStatuses[status] = c
+			}
+		}
+		exercised[method+" "+path] = ec
+		coverage.Endpoints = append(coverage.Endpoints, ec)
+	}
+
+	for path, item := range doc.Paths {
+		for method := range item.Operations() {
+			if _, ok := exercised[method+" "+path]; !ok {
+				coverage.Endpoints = append(coverage.Endpoints, &EndpointCoverage{Method: method, Path: path, Status: NotExercised})
+			}
+		}
+	}
+
+	slices.SortFunc(coverage.Endpoints, func(a, b *EndpointCoverage) int {
+		if c := cmp.Compare(a.Status, b.Status); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+	return coverage
+}
+
+// isDeclaredStatus reports whether the status code is declared as a response of the operation. e.g. "200", "2XX" or "default"
+func isDeclaredStatus(op *openapi.Operation, status int) bool {
+	s := strconv.Itoa(status)
+	for code := range op.Responses {
+		code = strings.ToUpper(code)
+		if code == s || code == "DEFAULT" || (len(s) == 3 && code == s[:1]+"XX") {
+			return true
+		}
+	}
+	return false
+}
+
+// Operations returns the number of the documented operations and the exercised ones
+func (c *Coverage) Operations() (int, int) {
+	var documented, exercised int
+	for _, e := range c.Endpoints {
+		switch e.Status {
+		case Covered:
+			documented++
+			exercised++
+		case NotExercised:
+			documented++
+		}
+	}
+	return documented, exercised
+}
@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/haijima/stool/internal/log"
+	"github.com/haijima/stool/internal/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCoverage(t *testing.T) {
+	doc, err := openapi.Load(strings.NewReader("openapi: 3.0.0\ninfo: {title: test, version: \"1\"}\npaths:\n  /users/{id}:\n    parameters:\n      - {name: id, in: path, schema: {type: integer}}\n    get:\n      parameters:\n        - {name: page, in: query}\n      responses:\n        \"2XX\": {description: OK}\n  /items:\n    get:\n      responses:\n        default: {description: OK}\n"))
+	require.NoError(t, err)
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /users/1?page=1&sort=asc HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\ntime:01/Jan/2023:12:00:01 +0900\treq:GET /users/abc HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\ntime:01/Jan/2023:12:00:02 +0900\treq:GET /users/2 HTTP/2.0\tstatus:404\tuidset:-\tuidgot:uid=A\ntime:01/Jan/2023:12:00:03 +0900\treq:DELETE /users/2 HTTP/2.0\tstatus:204\tuidset:-\tuidgot:uid=A\n")
+	logReader, _ := log.NewLTSVReader(stdin, log.LTSVReadOpt{MatchingGroups: CoverageMatchingGroups(doc, []string{"^/users/([^/]+)$"})})
+	param, err := NewParamProfiler().Profile(logReader)
+	require.NoError(t, err)
+
+	coverage := NewCoverage(doc, param)
+
+	assert.Equal(t, []*EndpointCoverage{
+		{Method: "GET", Path: "/users/{id}", Status: Covered, Count: 2, UndocumentedQueryKeys: map[string]int{"sort": 1}, UndeclaredStatuses: map[int]int{404: 1}},
+		{Method: "GET", Path: "/items", Status: NotExercised},
+		{Method: "DELETE", Path: "/users/{id}", Status: Undocumented, Count: 1},
+		{Method: "GET", Path: "^/users/([^/]+)$", Status: Undocumented, Count: 1},
+	}, coverage.Endpoints)
+	documented, exercised := coverage.Operations()
+	assert.Equal(t, 2, documented)
+	assert.Equal(t, 1, exercised)
+}
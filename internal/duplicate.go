@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+	"golang.org/x/exp/maps"
+)
+
+type DuplicateProfiler struct {
+}
+
+func NewDuplicateProfiler() *DuplicateProfiler {
+	return &DuplicateProfiler{}
+}
+
+// Profile finds requests in the same session with the same method and the same URI including the query within the window
+// after the previous one. The log entries are expected to be in chronological order.
+func (p *DuplicateProfiler) Profile(reader *log.LTSVReader, window time.Duration, opt SessionOption) (*Duplicate, error) {
+	result := &Duplicate{
+		Window:     window,
+		Count:      make(map[string]int),
+		Duplicates: make(map[string]int),
+		AfterError: make(map[string]int),
+		Gaps:       make(map[string][]time.Duration),
+	}
+	recent := make(map[string][]recentRequest) // session -> requests within the window in chronological order
+	sessionizer := NewSessionizer(opt)
+
+	var entry log.LogEntry
+	for reader.Read() {
+		_, err := reader.Parse(&entry)
+		if err != nil {
+			if err == log.Filtered {
+				continue
+			}
+			return nil, err
+		}
+
+		k := entry.Key()
+		result.Count[k] += 1
+
+		session, _ := sessionizer.Session(entry)
+		if session == "" { // requests without a uid or out of any session can not be attributed to a user
+			continue
+		}
+
+		method, path, query := log.ParseReq(entry.Req)
+		req := method + " " + path
+		if query != "" {
+			req += "?" + query
+		}
+
+		// drop the requests out of the window
+		rs := recent[session]
+		i := slices.IndexFunc(rs, func(r recentRequest) bool { return entry.Time.Sub(r.time) <= window })
+		if i < 0 {
+			rs = rs[:0]
+		} else {
+			rs = rs[i:]
+		}
+
+		if j := slices.IndexFunc(rs, func(r recentRequest) bool { return r.req == req }); j >= 0 {
+			result.Duplicates[k] += 1
+			result.Gaps[k] = append(result.Gaps[k], entry.Time.Sub(rs[j].time))
+			if isErrorStatus(rs[j].status) {
+				result.AfterError[k] += 1
+			}
+			rs = slices.Delete(rs, j, j+1) // the latest one is compared with the next request
+		}
+		recent[session] = append(rs, recentRequest{req: req, time: entry.Time, status: entry.Status})
+	}
+
+	result.Endpoints = maps.Keys(result.Count)
+	slices.SortFunc(result.Endpoints, func(a, b string) int {
+		if result.Duplicates[a] != result.Duplicates[b] {
+			return cmp.Compare(result.Duplicates[b], result.Duplicates[a])
+		}
+		return strings.Compare(a, b)
+	})
+	for _, gaps := range result.Gaps {
+		slices.Sort(gaps)
+	}
+	return result, nil
+}
+
+// Duplicate is the requests repeated in the same session within the window
+type Duplicate struct {
+	Window     time.Duration
+	Endpoints  []string                   // in descending order of duplicates
+	Count      map[string]int             // requests of each endpoint
+	Duplicates map[string]int             // requests repeating the previous one within the window
+	AfterError map[string]int             // duplicates following a response with 5xx or 499 status
+	Gaps       map[string][]time.Duration // time since the previous one of each duplicate in ascending order
+}
+
+// AllGaps returns the gaps of the duplicates of all endpoints in ascending order
+func (d *Duplicate) AllGaps() []time.Duration {
+	gaps := make([]time.Duration, 0)
+	for _, g := range d.Gaps {
+		gaps = append(gaps, g...)
+	}
+	slices.Sort(gaps)
+	return gaps
+}
+
+type recentRequest struct {
+	req    string
+	time   time.Time
+	status int
+}
+
+// isErrorStatus reports whether the status suggests the client will retry. 499 is the status of nginx for requests
+// closed by the client, which is typical of a client-side timeout.
+func isErrorStatus(status int) bool {
+	return status >= 500 || status == 499
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateProfiler_Profile(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:POST /api/orders HTTP/2.0\tstatus:504\tuidset:uid=A\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:POST /api/orders HTTP/2.0\tstatus:201\tuidset:-\tuidgot:uid=A\n" + // retry after 504
+		"time:01/Jan/2023:12:00:02 +0900\treq:POST /api/orders HTTP/2.0\tstatus:201\tuidset:uid=B\tuidgot:-\n" + // another user
+		"time:01/Jan/2023:12:00:03 +0900\treq:POST /api/orders HTTP/2.0\tstatus:201\tuidset:-\tuidgot:uid=A\n" + // double submit
+		"time:01/Jan/2023:12:00:04 +0900\treq:GET /api/items?page=1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:05 +0900\treq:GET /api/items?page=2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // different query
+		"time:01/Jan/2023:12:00:20 +0900\treq:GET /api/items?page=2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n") // out of the window
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{})
+	require.NoError(t, err)
+
+	duplicate, err := NewDuplicateProfiler().Profile(logReader, 10*time.Second, SessionOption{StartOnUidGot: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"POST /api/orders", "GET /api/items"}, duplicate.Endpoints)
+	assert.Equal(t, map[string]int{"POST /api/orders": 4, "GET /api/items": 3}, duplicate.Count)
+	assert.Equal(t, map[string]int{"POST /api/orders": 2}, duplicate.Duplicates)
+	assert.Equal(t, map[string]int{"POST /api/orders": 1}, duplicate.AfterError)
+	assert.Equal(t, map[string][]time.Duration{"POST /api/orders": {time.Second, 2 * time.Second}}, duplicate.Gaps)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, duplicate.AllGaps())
+}
+
+func TestDuplicateProfiler_Profile_Session(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:03 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // another session after idle
+		"time:01/Jan/2023:12:00:04 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=B\n" + // first seen via uidgot
+		"time:01/Jan/2023:12:00:05 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=B\n")
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{})
+	require.NoError(t, err)
+
+	duplicate, err := NewDuplicateProfiler().Profile(logReader, 10*time.Second, SessionOption{IdleTimeout: 2 * time.Second})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"GET /api/items": 4}, duplicate.Count)
+	assert.Empty(t, duplicate.Duplicates)
+}
+
+func TestDuplicateProfiler_Profile_NoSession(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=B\n") // another client out of any session
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{})
+	require.NoError(t, err)
+
+	duplicate, err := NewDuplicateProfiler().Profile(logReader, 10*time.Second, SessionOption{})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"GET /api/items": 2}, duplicate.Count)
+	assert.Empty(t, duplicate.Duplicates)
+}
@@ -6,6 +6,7 @@ type Edge struct {
 	Color    string
 	PenWidth float64
 	Weight   float64
+	Label    string
 }
 
 const DefaultEdgeColor = "black"
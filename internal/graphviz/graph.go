@@ -92,9 +92,13 @@ func (g *Graph) AddBoxNode(node *BoxNode) error {
 		return err
 	}
 
+	style := "filled"
+	if node.Dashed {
+		style = "\"filled,dashed\""
+	}
 	return graph.AddNode(g.name, node.Name, map[string]string{
 		"shape":     "box",
-		"style":     "filled",
+		"style":     style,
 		"fontname":  "Courier",
 		"penwidth":  "1",
 		"margin":    "0.2",
@@ -132,12 +136,17 @@ func (g *Graph) AddEdge(edge *Edge) error {
 		dir = "back"
 	}
 
-	return graph.AddEdge(edge.fromName, edge.toName, true, map[string]string{
+	attrs := map[string]string{
 		"color":    edge.Color,
 		"penwidth": fmt.Sprintf("%f", edge.PenWidth),
 		"weight":   fmt.Sprintf("%f", edge.Weight),
 		"dir":      dir,
-	})
+	}
+	if edge.Label != "" {
+		attrs["label"] = edge.Label
+		attrs["fontname"] = "Courier"
+	}
+	return graph.AddEdge(edge.fromName, edge.toName, true, attrs)
 }
 
 func (g *Graph) Write(w io.Writer) error {
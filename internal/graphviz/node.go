@@ -14,6 +14,7 @@ type BoxNode struct {
 	TextNode
 	Color     string
 	FillColor string
+	Dashed    bool
 }
 
 func NewTextNode(name string, title string) *TextNode {
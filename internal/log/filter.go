@@ -22,6 +22,7 @@ func NewFilterExpr(code string) (*FilterExpr, error) {
 		cel.Variable("time", cel.TimestampType),
 		cel.Variable("uid", cel.StringType),
 		cel.Variable("set_new_uid", cel.BoolType),
+		cel.Variable("reqtime", cel.DoubleType),
 	)
 	if err != nil {
 		return nil, err
@@ -47,6 +48,7 @@ func (f *FilterExpr) Run(entry LogEntry) (bool, error) {
 		"time":        entry.Time,
 		"uid":         entry.Uid,
 		"set_new_uid": entry.SetNewUid,
+		"reqtime":     entry.ReqTime.Seconds(),
 	})
 	if err != nil {
 		return false, err
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/url"
 	"regexp"
 	"strconv"
@@ -79,11 +80,12 @@ func NewLTSVReader(r io.Reader, opt LTSVReadOpt) (*LTSVReader, error) {
 }
 
 var defaultLabels = map[string]string{
-	"req":    "req",
-	"status": "status",
-	"time":   "time",
-	"uidset": "uidset",
-	"uidgot": "uidgot",
+	"req":     "req",
+	"status":  "status",
+	"time":    "time",
+	"uidset":  "uidset",
+	"uidgot":  "uidgot",
+	"reqtime": "reqtime",
 }
 
 type LogEntry struct {
@@ -94,6 +96,8 @@ type LogEntry struct {
 	Uid          string
 	SetNewUid    bool
 	Time         time.Time
+	ReqTime      time.Duration // response time. valid only if HasReqTime is true
+	HasReqTime   bool          // whether the optional "reqtime" field is found
 	MatchedGroup *regexp.Regexp
 }
 
@@ -120,6 +124,8 @@ func (r *LTSVReader) Parse(entry *LogEntry) (*LogEntry, error) {
 	entry.Uri = ""
 	entry.Status = 0
 	entry.Time = time.Time{}
+	entry.ReqTime = 0
+	entry.HasReqTime = false
 	entry.Uid = ""
 	entry.SetNewUid = false
 	entry.MatchedGroup = nil
@@ -147,6 +153,13 @@ func (r *LTSVReader) Parse(entry *LogEntry) (*LogEntry, error) {
 			}
 			entry.Time = reqTime
 
+		case r.labels["reqtime"]:
+			// reqtime is optional, so a value which is not in seconds like $request_time of nginx is ignored
+			if sec, err := strconv.ParseFloat(string(value), 64); err == nil && sec >= 0 && sec < math.MaxInt64/float64(time.Second) {
+				entry.ReqTime = time.Duration(sec * float64(time.Second))
+				entry.HasReqTime = true
+			}
+
 		case r.labels["uidset"]:
 			if string(value) != "" && string(value) != "-" {
 				if i := strings.Index(string(value), "="); i >= 0 {
@@ -1,10 +1,14 @@
 package log
 
 import (
+	"bytes"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func BenchmarkLTSVReader(b *testing.B) {
@@ -40,3 +44,33 @@ func BenchmarkLTSVReader(b *testing.B) {
 		_ = f.Close()
 	}
 }
+
+func TestLTSVReader_Parse_reqtime(t *testing.T) {
+	tests := []struct {
+		name    string
+		reqtime string
+		want    time.Duration
+		has     bool
+	}{
+		{name: "seconds", reqtime: "0.250", want: 250 * time.Millisecond, has: true},
+		{name: "missing", reqtime: "-", has: false},
+		{name: "invalid", reqtime: "0.250, 0.100", has: false},
+		{name: "negative", reqtime: "-1", has: false},
+		{name: "infinite", reqtime: "inf", has: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET / HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\treqtime:" + tt.reqtime + "\n")
+			logReader, err := NewLTSVReader(stdin, LTSVReadOpt{})
+			require.NoError(t, err)
+
+			var entry LogEntry
+			require.True(t, logReader.Read())
+			_, err = logReader.Parse(&entry)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, entry.ReqTime)
+			assert.Equal(t, tt.has, entry.HasReqTime)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"strings"
+)
+
+type MatchingGroupStatus int
+
+const (
+	MatchingGroupKept    MatchingGroupStatus = iota // exists in both of the current config and the source
+	MatchingGroupAdded                              // newly discovered from the source
+	MatchingGroupRemoved                            // exists in the current config but not found in the source
+)
+
+type MatchingGroup struct {
+	Pattern string
+	Status  MatchingGroupStatus
+}
+
+// MergeMatchingGroups merges newly discovered patterns into the current patterns.
+// The order and the regular expressions of the current patterns are kept as they are.
+// Patterns are compared by their skeleton, so a hand-tuned pattern like "^/users/([0-9]+)$" matches "^/users/([^/]+)$".
+// Added patterns are placed right after the nearest preceding pattern in the discovered order.
+func MergeMatchingGroups(current, discovered []string) []MatchingGroup {
+	discoveredSkeletons := make(map[string]struct{}, len(discovered))
+	for _, d := range discovered {
+		discoveredSkeletons[patternSkeleton(d)] = struct{}{}
+	}
+
+	result := make([]MatchingGroup, 0, len(current)+len(discovered))
+	positions := make(map[string]int, len(current)) // skeleton -> index in result
+	for _, c := range current {
+		s := patternSkeleton(c)
+		status := MatchingGroupKept
+		if _, ok := discoveredSkeletons[s]; !ok {
+			status = MatchingGroupRemoved
+		}
+		if _, ok := positions[s]; !ok {
+			positions[s] = len(result)
+		}
+		result = append(result, MatchingGroup{Pattern: c, Status: status})
+	}
+
+	insertAt := 0
+	for _, d := range discovered {
+		s := patternSkeleton(d)
+		if i, ok := positions[s]; ok {
+			insertAt = i + 1
+			continue
+		}
+		result = append(result[:insertAt], append([]MatchingGroup{{Pattern: d, Status: MatchingGroupAdded}}, result[insertAt:]...)...)
+		for k, i := range positions {
+			if i >= insertAt {
+				positions[k] = i + 1
+			}
+		}
+		positions[s] = insertAt
+		insertAt++
+	}
+	return result
+}
+
+// patternSkeleton replaces each group of the regular expression with "()" to compare patterns regardless of the content of the groups.
+func patternSkeleton(pattern string) string {
+	var sb strings.Builder
+	depth := 0
+	escaped := false
+	for _, r := range pattern {
+		if escaped {
+			escaped = false
+			if depth == 0 {
+				sb.WriteRune('\\')
+				sb.WriteRune(r)
+			}
+			continue
+		}
+		switch r {
+		case '\\':
+			escaped = true
+		case '(':
+			if depth == 0 {
+				sb.WriteString("()")
+			}
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		default:
+			if depth == 0 {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeMatchingGroups(t *testing.T) {
+	current := []string{"^/api/users/(?P<id>[0-9]+)$", "^/api/old$", "^/assets/.*"}
+	discovered := []string{"^/api/users/([^/]+)$", "^/api/users$", "^/api/items$"}
+
+	merged := MergeMatchingGroups(current, discovered)
+
+	assert.Equal(t, []MatchingGroup{
+		{Pattern: "^/api/users/(?P<id>[0-9]+)$", Status: MatchingGroupKept},
+		{Pattern: "^/api/users$", Status: MatchingGroupAdded},
+		{Pattern: "^/api/items$", Status: MatchingGroupAdded},
+		{Pattern: "^/api/old$", Status: MatchingGroupRemoved},
+		{Pattern: "^/assets/.*", Status: MatchingGroupRemoved},
+	}, merged)
+}
+
+func TestMergeMatchingGroups_Empty(t *testing.T) {
+	merged := MergeMatchingGroups(nil, []string{"^/b$", "^/a$"})
+
+	assert.Equal(t, []MatchingGroup{
+		{Pattern: "^/b$", Status: MatchingGroupAdded},
+		{Pattern: "^/a$", Status: MatchingGroupAdded},
+	}, merged)
+}
+
+func Test_patternSkeleton(t *testing.T) {
+	assert.Equal(t, "^/users/()/posts/()$", patternSkeleton("^/users/([^/]+)/posts/(?P<id>[0-9]+)$"))
+	assert.Equal(t, "^/users/()$", patternSkeleton("^/users/((?:a|b)[0-9]+)$"))
+	assert.Equal(t, "^/users/\\(me\\)$", patternSkeleton("^/users/\\(me\\)$"))
+}
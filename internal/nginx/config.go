@@ -0,0 +1,229 @@
+package nginx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Directive is a simple or block directive of nginx configuration
+type Directive struct {
+	Name  string
+	Args  []string
+	Block []Directive
+}
+
+// ParseConfig parses the nginx configuration file. "include" directives are expanded in place.
+// Relative paths of "include" are resolved from the directory of the given file.
+func ParseConfig(fs afero.Fs, fileName string) ([]Directive, error) {
+	return parseFile(fs, fileName, filepath.Dir(fileName), 0)
+}
+
+const maxIncludeDepth = 16
+
+func parseFile(fs afero.Fs, fileName, baseDir string, depth int) ([]Directive, error) {
+	if depth > maxIncludeDepth {
+		return nil, fmt.Errorf("too deep include: %s", fileName)
+	}
+	f, err := fs.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := &parser{r: bufio.NewReader(f), fileName: fileName, line: 1}
+	directives, err := p.parseBlock(false)
+	if err != nil {
+		return nil, err
+	}
+	return expandInclude(fs, directives, baseDir, depth)
+}
+
+func expandInclude(fs afero.Fs, directives []Directive, baseDir string, depth int) ([]Directive, error) {
+	result := make([]Directive, 0, len(directives))
+	for _, d := range directives {
+		if d.Name != "include" || len(d.Args) != 1 {
+			if d.Block != nil {
+				block, err := expandInclude(fs, d.Block, baseDir, depth)
+				if err != nil {
+					return nil, err
+				}
+				d.Block = block
+			}
+			result = append(result, d)
+			continue
+		}
+
+		pattern := d.Args[0]
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		files, err := afero.Glob(fs, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			included, err := parseFile(fs, file, baseDir, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, included...)
+		}
+	}
+	return result, nil
+}
+
+type parser struct {
+	r        *bufio.Reader
+	fileName string
+	line     int
+}
+
+func (p *parser) parseBlock(inBlock bool) ([]Directive, error) {
+	directives := make([]Directive, 0)
+	var current []string
+	for {
+		tok, quoted, err := p.next()
+		if err == io.EOF {
+			if inBlock {
+				return nil, fmt.Errorf("%s:%d: unexpected end of file, expecting \"}\"", p.fileName, p.line)
+			}
+			if len(current) > 0 {
+				return nil, fmt.Errorf("%s:%d: unexpected end of file, expecting \";\" or \"}\"", p.fileName, p.line)
+			}
+			return directives, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case tok == ";" && !quoted:
+			if len(current) == 0 {
+				return nil, fmt.Errorf("%s:%d: unexpected \";\"", p.fileName, p.line)
+			}
+			directives = append(directives, Directive{Name: current[0], Args: current[1:]})
+			current = nil
+		case tok == "{" && !quoted:
+			if len(current) == 0 {
+				return nil, fmt.Errorf("%s:%d: unexpected \"{\"", p.fileName, p.line)
+			}
+			block, err := p.parseBlock(true)
+			if err != nil {
+				return nil, err
+			}
+			directives = append(directives, Directive{Name: current[0], Args: current[1:], Block: block})
+			current = nil
+		case tok == "}" && !quoted:
+			if !inBlock || len(current) > 0 {
+				return nil, fmt.Errorf("%s:%d: unexpected \"}\"", p.fileName, p.line)
+			}
+			return directives, nil
+		default:
+			current = append(current, tok)
+		}
+	}
+}
+
+// next returns the next token. Comments are skipped and quotes are removed.
+func (p *parser) next() (string, bool, error) {
+	// skip spaces and comments
+	for {
+		r, _, err := p.r.ReadRune()
+		if err != nil {
+			return "", false, err
+		}
+		if r == '\n' {
+			p.line++
+		}
+		if r == '#' {
+			if _, err := p.r.ReadString('\n'); err != nil {
+				return "", false, err
+			}
+			p.line++
+			continue
+		}
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+			continue
+		}
+		if r == ';' || r == '{' || r == '}' {
+			return string(r), false, nil
+		}
+		if r == '"' || r == '\'' {
+			s, err := p.quoted(r)
+			return s, true, err
+		}
+		_ = p.r.UnreadRune()
+		break
+	}
+
+	var sb strings.Builder
+	for {
+		r, _, err := p.r.ReadRune()
+		if err == io.EOF {
+			return sb.String(), false, nil
+		} else if err != nil {
+			return "", false, err
+		}
+		switch r {
+		case ' ', '\t', '\r', '\n', ';', '{', '}':
+			_ = p.r.UnreadRune()
+			return sb.String(), false, nil
+		case '\\':
+			next, _, err := p.r.ReadRune()
+			if err != nil {
+				return "", false, err
+			}
+			writeEscaped(&sb, next)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+func (p *parser) quoted(quote rune) (string, error) {
+	var sb strings.Builder
+	for {
+		r, _, err := p.r.ReadRune()
+		if err == io.EOF {
+			return "", fmt.Errorf("%s:%d: unexpected end of file in a quoted string", p.fileName, p.line)
+		} else if err != nil {
+			return "", err
+		}
+		switch r {
+		case quote:
+			return sb.String(), nil
+		case '\\':
+			next, _, err := p.r.ReadRune()
+			if err != nil {
+				return "", err
+			}
+			writeEscaped(&sb, next)
+		case '\n':
+			p.line++
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// writeEscaped writes the escaped rune in the same way as nginx. Unknown escapes like "\." are kept for regular expressions.
+func writeEscaped(sb *strings.Builder, r rune) {
+	switch r {
+	case '"', '\'', '\\':
+		sb.WriteRune(r)
+	case 't':
+		sb.WriteRune('\t')
+	case 'r':
+		sb.WriteRune('\r')
+	case 'n':
+		sb.WriteRune('\n')
+	default:
+		sb.WriteRune('\\')
+		sb.WriteRune(r)
+	}
+}
@@ -0,0 +1,51 @@
+package nginx
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/etc/nginx/nginx.conf", []byte(`# main config
+http {
+    include sites-enabled/*.conf;
+    log_format ltsv "time:$time_local"
+                    "\treq:$request";
+}
+`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/etc/nginx/sites-enabled/app.conf", []byte(`server {
+    listen 80;
+    location = / { return 200; }
+    location ~ "^/api/users/(\d+)$" { proxy_pass http://app; } # regex
+    location ~* \.(png|css)$ { expires 1d; }
+}
+`), 0644))
+
+	directives, err := ParseConfig(fs, "/etc/nginx/nginx.conf")
+
+	require.NoError(t, err)
+	require.Len(t, directives, 1)
+	http := directives[0]
+	assert.Equal(t, "http", http.Name)
+	require.Len(t, http.Block, 2)
+	assert.Equal(t, "server", http.Block[0].Name)
+	assert.Equal(t, Directive{Name: "log_format", Args: []string{"ltsv", "time:$time_local", "\treq:$request"}}, http.Block[1])
+	assert.Equal(t, []Location{
+		{Modifier: "=", Path: "/"},
+		{Modifier: "~", Path: `^/api/users/(\d+)$`},
+		{Modifier: "~*", Path: `\.(png|css)$`},
+	}, Locations(directives))
+}
+
+func TestParseConfig_Error(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "nginx.conf", []byte("server {\n    listen 80;\n"), 0644))
+
+	_, err := ParseConfig(fs, "nginx.conf")
+
+	assert.ErrorContains(t, err, "nginx.conf:3: unexpected end of file")
+}
@@ -0,0 +1,107 @@
+package nginx
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Location is a "location" block of nginx configuration
+type Location struct {
+	Modifier string // one of "", "=", "^~", "~" and "~*"
+	Path     string
+}
+
+// Locations returns all the "location" blocks including nested ones in the order of appearance.
+// Named locations like "@fallback" are ignored.
+func Locations(directives []Directive) []Location {
+	locations := make([]Location, 0)
+	for _, d := range directives {
+		if d.Name == "location" {
+			if l, ok := newLocation(d.Args); ok {
+				locations = append(locations, l)
+			}
+		}
+		if d.Block != nil {
+			locations = append(locations, Locations(d.Block)...)
+		}
+	}
+	return locations
+}
+
+func newLocation(args []string) (Location, bool) {
+	switch len(args) {
+	case 1:
+		// modifier can be attached to the path like "=/foo"
+		for _, m := range []string{"=", "^~", "~*", "~"} {
+			if strings.HasPrefix(args[0], m) && len(args[0]) > len(m) {
+				return Location{Modifier: m, Path: args[0][len(m):]}, true
+			}
+		}
+		if strings.HasPrefix(args[0], "@") {
+			return Location{}, false
+		}
+		return Location{Path: args[0]}, true
+	case 2:
+		switch args[0] {
+		case "=", "^~", "~", "~*":
+			return Location{Modifier: args[0], Path: args[1]}, true
+		}
+	}
+	return Location{}, false
+}
+
+// Regexp converts the location into the regular expression
+func (l Location) Regexp() string {
+	switch l.Modifier {
+	case "=":
+		return "^" + regexp.QuoteMeta(l.Path) + "$"
+	case "~":
+		return l.Path
+	case "~*":
+		return "(?i)" + l.Path
+	default:
+		return "^" + regexp.QuoteMeta(l.Path)
+	}
+}
+
+// MatchingGroups converts the locations into regular expressions in the priority order of nginx.
+//  1. exact match locations ("=")
+//  2. prefix locations that disable regular expressions ("^~"), longest first
+//  3. regular expression locations ("~" and "~*") in the order of appearance
+//  4. other prefix locations, longest first
+//
+// This is an approximation because nginx prefers a longer prefix location to a shorter "^~" location.
+// Regular expressions that Go does not support are skipped with a warning.
+func MatchingGroups(locations []Location) []string {
+	priority := map[string]int{"=": 0, "^~": 1, "~": 2, "~*": 2, "": 3}
+	sorted := slices.Clone(locations)
+	slices.SortStableFunc(sorted, func(a, b Location) int {
+		if c := cmp.Compare(priority[a.Modifier], priority[b.Modifier]); c != 0 {
+			return c
+		}
+		if a.Modifier == "~" || a.Modifier == "~*" {
+			return 0 // keep the order of appearance
+		}
+		return cmp.Compare(len(b.Path), len(a.Path))
+	})
+
+	matchingGroups := make([]string, 0, len(sorted))
+	seen := make(map[string]struct{}, len(sorted))
+	for _, l := range sorted {
+		re := l.Regexp()
+		if _, err := regexp.Compile(re); err != nil {
+			slog.Warn(fmt.Sprintf("skip the location %q: %v", l.Path, err))
+			continue
+		}
+		if _, ok := seen[re]; ok {
+			continue
+		}
+		seen[re] = struct{}{}
+		matchingGroups = append(matchingGroups, re)
+	}
+	return matchingGroups
+}
@@ -0,0 +1,48 @@
+package nginx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocations(t *testing.T) {
+	directives := []Directive{
+		{Name: "server", Block: []Directive{
+			{Name: "location", Args: []string{"/"}, Block: []Directive{
+				{Name: "location", Args: []string{"=/favicon.ico"}, Block: []Directive{}},
+			}},
+			{Name: "location", Args: []string{"@fallback"}, Block: []Directive{}},
+			{Name: "location", Args: []string{"^~", "/static/"}, Block: []Directive{}},
+		}},
+	}
+
+	assert.Equal(t, []Location{
+		{Modifier: "", Path: "/"},
+		{Modifier: "=", Path: "/favicon.ico"},
+		{Modifier: "^~", Path: "/static/"},
+	}, Locations(directives))
+}
+
+func TestMatchingGroups(t *testing.T) {
+	locations := []Location{
+		{Modifier: "", Path: "/"},
+		{Modifier: "~", Path: `^/api/users/(\d+)$`},
+		{Modifier: "", Path: "/api/"},
+		{Modifier: "^~", Path: "/static/"},
+		{Modifier: "~*", Path: `\.(png|css)$`},
+		{Modifier: "=", Path: "/index.html"},
+		{Modifier: "~", Path: `^/(?=api)`}, // unsupported by Go
+		{Modifier: "^~", Path: "/static/img/"},
+	}
+
+	assert.Equal(t, []string{
+		`^/index\.html$`,
+		`^/static/img/`,
+		`^/static/`,
+		`^/api/users/(\d+)$`,
+		`(?i)\.(png|css)$`,
+		`^/api/`,
+		`^/`,
+	}, MatchingGroups(locations))
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/haijima/stool/internal/openapi"
+	"golang.org/x/exp/maps"
+)
+
+// NewOpenAPI builds an OpenAPI document from the observed requests.
+// Endpoints grouped by matching groups are converted into path templates with their capture groups as path parameters.
+func NewOpenAPI(param *Param, title, version string) *openapi.Document {
+	doc := &openapi.Document{
+		OpenAPI: "3.0.3",
+		Info:    openapi.Info{Title: title, Version: version},
+		Paths:   make(map[string]*openapi.PathItem),
+	}
+
+	for _, k := range param.Endpoints {
+		method, uri, _ := strings.Cut(k, " ")
+		path := uri
+		var pathNames []string
+		if param.Matched[k] {
+			var ok bool
+			path, pathNames, ok = openapi.PathTemplate(uri)
+			if !ok {
+				slog.Warn(fmt.Sprintf("skip %q: only literals, capture groups and anchors are supported to convert into the path", k))
+				continue
+			}
+		}
+
+		op := &openapi.Operation{
+			Summary:    fmt.Sprintf("%d requests observed", param.Count[k]),
+			Parameters: make([]*openapi.Parameter, 0),
+			Responses:  make(map[string]*openapi.Response),
+		}
+		for i, name := range pathNames {
+			var values map[string]int
+			if i < len(param.Path[k]) {
+				values = param.Path[k][i]
+			}
+			op.Parameters = append(op.Parameters, &openapi.Parameter{Name: name, In: "path", Required: true, Schema: inferSchema(values)})
+		}
+		queryKeys := maps.Keys(param.QueryValue[k])
+		slices.Sort(queryKeys)
+		for _, qk := range queryKeys {
+			op.Parameters = append(op.Parameters, &openapi.Parameter{
+				Name:     qk,
+				In:       "query",
+				Required: param.QueryKey[k][qk] == param.Count[k],
+				Schema:   inferSchema(param.QueryValue[k][qk]),
+			})
+		}
+		for status := range param.Status[k] {
+			op.Responses[strconv.Itoa(status)] = &openapi.Response{Description: http.StatusText(status)}
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &openapi.PathItem{}
+			doc.Paths[path] = item
+		}
+		if err := item.SetOperation(method, op); err != nil {
+			slog.Warn(fmt.Sprintf("skip %q: %v", k, err))
+		}
+	}
+	return doc
+}
+
+// inferSchema infers the schema of the parameter from the observed values
+func inferSchema(values map[string]int) *openapi.Schema {
+	switch InferParamType(values) {
+	case ParamTypeInteger:
+		return &openapi.Schema{Type: "integer"}
+	case ParamTypeFloat:
+		return &openapi.Schema{Type: "number"}
+	case ParamTypeBoolean:
+		return &openapi.Schema{Type: "boolean"}
+	case ParamTypeUUID:
+		return &openapi.Schema{Type: "string", Format: "uuid"}
+	case ParamTypeEnum:
+		enum := maps.Keys(values)
+		slices.Sort(enum)
+		s := &openapi.Schema{Type: "string", Enum: make([]any, 0, len(enum))}
+		for _, e := range enum {
+			s.Enum = append(s.Enum, e)
+		}
+		return s
+	default:
+		return &openapi.Schema{Type: "string"}
+	}
+}
@@ -0,0 +1,293 @@
+package openapi
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a subset of the OpenAPI 3 document that stool handles
+type Document struct {
+	OpenAPI    string               `json:"openapi" yaml:"openapi"`
+	Info       Info                 `json:"info" yaml:"info"`
+	Paths      map[string]*PathItem `json:"paths" yaml:"paths"`
+	Components *Components          `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title" yaml:"title"`
+	Version string `json:"version" yaml:"version"`
+}
+
+type Components struct {
+	Parameters map[string]*Parameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Schemas    map[string]*Schema    `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+}
+
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Get        *Operation   `json:"get,omitempty" yaml:"get,omitempty"`
+	Put        *Operation   `json:"put,omitempty" yaml:"put,omitempty"`
+	Post       *Operation   `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete     *Operation   `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options    *Operation   `json:"options,omitempty" yaml:"options,omitempty"`
+	Head       *Operation   `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch      *Operation   `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Trace      *Operation   `json:"trace,omitempty" yaml:"trace,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Responses   map[string]*Response `json:"responses" yaml:"responses"`
+}
+
+type Parameter struct {
+	Ref      string  `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Name     string  `json:"name,omitempty" yaml:"name,omitempty"`
+	In       string  `json:"in,omitempty" yaml:"in,omitempty"`
+	Required bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+type Schema struct {
+	Ref    string  `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type   string  `json:"type,omitempty" yaml:"type,omitempty"`
+	Format string  `json:"format,omitempty" yaml:"format,omitempty"`
+	Enum   []any   `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Items  *Schema `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+type Response struct {
+	Description string `json:"description" yaml:"description"`
+}
+
+// Load reads an OpenAPI document written in YAML or JSON
+func Load(r io.Reader) (*Document, error) {
+	var doc Document
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("only OpenAPI 3 is supported. but: %q", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+var methods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE"}
+
+// Operations returns the operations of the path item keyed by the upper-case HTTP method
+func (p *PathItem) Operations() map[string]*Operation {
+	ops := make(map[string]*Operation)
+	for i, op := range []*Operation{p.Get, p.Put, p.Post, p.Delete, p.Options, p.Head, p.Patch, p.Trace} {
+		if op != nil {
+			ops[methods[i]] = op
+		}
+	}
+	return ops
+}
+
+// SetOperation sets the operation of the path item for the HTTP method
+func (p *PathItem) SetOperation(method string, op *Operation) error {
+	switch strings.ToUpper(method) {
+	case "GET":
+		p.Get = op
+	case "PUT":
+		p.Put = op
+	case "POST":
+		p.Post = op
+	case "DELETE":
+		p.Delete = op
+	case "OPTIONS":
+		p.Options = op
+	case "HEAD":
+		p.Head = op
+	case "PATCH":
+		p.Patch = op
+	case "TRACE":
+		p.Trace = op
+	default:
+		return fmt.Errorf("unsupported method: %s", method)
+	}
+	return nil
+}
+
+// Parameter resolves "$ref" of the parameter
+func (d *Document) Parameter(p *Parameter) *Parameter {
+	if p.Ref == "" || d.Components == nil {
+		return p
+	}
+	if resolved, ok := d.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]; ok {
+		return resolved
+	}
+	return p
+}
+
+// Schema resolves "$ref" of the schema
+func (d *Document) Schema(s *Schema) *Schema {
+	if s == nil || s.Ref == "" || d.Components == nil {
+		return s
+	}
+	if resolved, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]; ok {
+		return resolved
+	}
+	return s
+}
+
+// PathParameters returns the path parameters of the path keyed by their names.
+// Parameters of operations override the ones of the path item.
+func (d *Document) PathParameters(path string) map[string]*Parameter {
+	params := make(map[string]*Parameter)
+	item, ok := d.Paths[path]
+	if !ok {
+		return params
+	}
+	add := func(ps []*Parameter) {
+		for _, p := range ps {
+			if p = d.Parameter(p); p.In == "path" {
+				params[p.Name] = p
+			}
+		}
+	}
+	add(item.Parameters)
+	for _, m := range methods {
+		if op, ok := item.Operations()[m]; ok {
+			add(op.Parameters)
+		}
+	}
+	return params
+}
+
+// MatchingGroups converts the paths into regular expressions with named capture groups.
+// Literal paths are placed before templated ones so that they win on matching.
+func (d *Document) MatchingGroups() []string {
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+	slices.SortFunc(paths, comparePathTemplate)
+
+	matchingGroups := make([]string, 0, len(paths))
+	for _, path := range paths {
+		matchingGroups = append(matchingGroups, d.PathRegexp(path))
+	}
+	return slices.Compact(matchingGroups)
+}
+
+var templateRegexp = regexp.MustCompile(`\{([^}]+)}`)
+var invalidGroupNameRegexp = regexp.MustCompile(`\W`)
+
+// PathRegexp converts the path template like "/users/{id}" into the regular expression like "^/users/(?P<id>[0-9]+)$"
+func (d *Document) PathRegexp(path string) string {
+	params := d.PathParameters(path)
+	var sb strings.Builder
+	sb.WriteString("^")
+	last := 0
+	for _, loc := range templateRegexp.FindAllStringSubmatchIndex(path, -1) {
+		sb.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+		name := path[loc[2]:loc[3]]
+		var schema *Schema
+		if p, ok := params[name]; ok {
+			schema = d.Schema(p.Schema)
+		}
+		sb.WriteString(fmt.Sprintf("(?P<%s>%s)", invalidGroupNameRegexp.ReplaceAllString(name, "_"), schemaRegexp(schema)))
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(path[last:]))
+	sb.WriteString("$")
+	return sb.String()
+}
+
+const (
+	integerPattern = `-?[0-9]+`
+	numberPattern  = `-?[0-9]+(?:\.[0-9]+)?`
+	uuidPattern    = `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
+	defaultPattern = `[^/]+`
+)
+
+// schemaRegexp returns the tightest regular expression for the schema of the path parameter
+func schemaRegexp(s *Schema) string {
+	if s == nil {
+		return defaultPattern
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			values = append(values, regexp.QuoteMeta(fmt.Sprint(e)))
+		}
+		return strings.Join(values, "|")
+	}
+	switch s.Type {
+	case "integer":
+		return integerPattern
+	case "number":
+		return numberPattern
+	case "boolean":
+		return "true|false"
+	case "string":
+		if s.Format == "uuid" {
+			return uuidPattern
+		}
+	}
+	return defaultPattern
+}
+
+// comparePathTemplate orders paths segment by segment. A literal segment comes before a templated one.
+func comparePathTemplate(a, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		at, bt := strings.Contains(as[i], "{"), strings.Contains(bs[i], "{")
+		if at != bt {
+			if at {
+				return 1
+			}
+			return -1
+		}
+	}
+	if c := cmp.Compare(strings.Count(a, "{"), strings.Count(b, "{")); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// PathTemplate converts the regular expression like "^/users/(?P<id>[0-9]+)$" into the path template like "/users/{id}".
+// Unnamed capture groups are named "param1", "param2", ... by their positions.
+// It returns false if the regular expression has other constructs than literals, capture groups and anchors.
+func PathTemplate(pattern string) (string, []string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", nil, false
+	}
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	}
+
+	var sb strings.Builder
+	names := make([]string, 0)
+	for _, sub := range subs {
+		switch sub.Op {
+		case syntax.OpBeginText, syntax.OpBeginLine, syntax.OpEndText, syntax.OpEndLine:
+			// skip anchors
+		case syntax.OpLiteral:
+			sb.WriteString(string(sub.Rune))
+		case syntax.OpCapture:
+			name := sub.Name
+			if name == "" {
+				name = fmt.Sprintf("param%d", sub.Cap)
+			}
+			names = append(names, name)
+			sb.WriteString("{" + name + "}")
+		default:
+			return "", nil, false
+		}
+	}
+	return sb.String(), names, true
+}
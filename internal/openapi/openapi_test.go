@@ -0,0 +1,128 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const spec = `openapi: 3.0.3
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      responses:
+        "200":
+          description: OK
+  /users/me:
+    get:
+      responses:
+        "200":
+          description: OK
+  /users/{user-id}/items/{item_id}:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/UserId'
+        - name: item_id
+          in: path
+          required: true
+          schema:
+            $ref: '#/components/schemas/Uuid'
+      responses:
+        "200":
+          description: OK
+  /files/{kind}/{name}.json:
+    get:
+      parameters:
+        - name: kind
+          in: path
+          schema:
+            type: string
+            enum: [image, text]
+      responses:
+        "200":
+          description: OK
+  /users:
+    post:
+      responses:
+        "201":
+          description: Created
+components:
+  parameters:
+    UserId:
+      name: user-id
+      in: path
+      required: true
+      schema:
+        type: integer
+  schemas:
+    Uuid:
+      type: string
+      format: uuid
+`
+
+func TestLoad(t *testing.T) {
+	doc, err := Load(strings.NewReader(spec))
+
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Len(t, doc.Paths, 5)
+	assert.Contains(t, doc.Paths["/users/{id}"].Operations(), "GET")
+	assert.Contains(t, doc.Paths["/users"].Operations(), "POST")
+	assert.Equal(t, "Created", doc.Paths["/users"].Post.Responses["201"].Description)
+}
+
+func TestLoad_Swagger2(t *testing.T) {
+	_, err := Load(strings.NewReader("swagger: \"2.0\"\n"))
+
+	assert.ErrorContains(t, err, "only OpenAPI 3 is supported")
+}
+
+func TestDocument_MatchingGroups(t *testing.T) {
+	doc, err := Load(strings.NewReader(spec))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"^/users$",
+		"^/users/me$",
+		"^/users/(?P<id>-?[0-9]+)$",
+		"^/users/(?P<user_id>-?[0-9]+)/items/(?P<item_id>[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})$",
+		"^/files/(?P<kind>image|text)/(?P<name>[^/]+)\\.json$",
+	}, doc.MatchingGroups())
+}
+
+func TestPathTemplate(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		names   []string
+		ok      bool
+	}{
+		{pattern: "^/users/(?P<id>[0-9]+)$", want: "/users/{id}", names: []string{"id"}, ok: true},
+		{pattern: "^/users/([^/]+)/items/([^/]+)$", want: "/users/{param1}/items/{param2}", names: []string{"param1", "param2"}, ok: true},
+		{pattern: "^/$", want: "/", names: []string{}, ok: true},
+		{pattern: "^/files/([^/]+)\\.json$", want: "/files/{param1}.json", names: []string{"param1"}, ok: true},
+		{pattern: "/assets/.*", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, names, ok := PathTemplate(tt.pattern)
+
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.names, names)
+			}
+		})
+	}
+}
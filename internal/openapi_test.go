@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/haijima/stool/internal/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOpenAPI(t *testing.T) {
+	param := &Param{
+		Endpoints:  []string{"GET ^/users/(?P<id>[0-9]+)$", "POST /users"},
+		Count:      map[string]int{"GET ^/users/(?P<id>[0-9]+)$": 10, "POST /users": 1},
+		Path:       map[string][]map[string]int{"GET ^/users/(?P<id>[0-9]+)$": {{"1": 5, "2": 5}}},
+		PathName:   map[string][]string{"GET ^/users/(?P<id>[0-9]+)$": {"id"}},
+		Matched:    map[string]bool{"GET ^/users/(?P<id>[0-9]+)$": true},
+		Status:     map[string]map[int]int{"GET ^/users/(?P<id>[0-9]+)$": {200: 9, 404: 1}, "POST /users": {201: 1}},
+		QueryKey:   map[string]map[string]int{"GET ^/users/(?P<id>[0-9]+)$": {"lang": 10, "page": 3}},
+		QueryValue: map[string]map[string]map[string]int{"GET ^/users/(?P<id>[0-9]+)$": {"lang": {"en": 6, "ja": 4}, "page": {"1": 2, "2": 1}}},
+	}
+
+	doc := NewOpenAPI(param, "test", "1.0.0")
+
+	assert.Equal(t, "test", doc.Info.Title)
+	require.Contains(t, doc.Paths, "/users/{id}")
+	require.Contains(t, doc.Paths, "/users")
+	get := doc.Paths["/users/{id}"].Get
+	require.NotNil(t, get)
+	assert.Equal(t, []*openapi.Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}},
+		{Name: "lang", In: "query", Required: true, Schema: &openapi.Schema{Type: "string", Enum: []any{"en", "ja"}}},
+		{Name: "page", In: "query", Required: false, Schema: &openapi.Schema{Type: "integer"}},
+	}, get.Parameters)
+	assert.Equal(t, map[string]*openapi.Response{"200": {Description: "OK"}, "404": {Description: "Not Found"}}, get.Responses)
+	assert.Equal(t, map[string]*openapi.Response{"201": {Description: "Created"}}, doc.Paths["/users"].Post.Responses)
+}
+
+func Test_inferSchema(t *testing.T) {
+	assert.Equal(t, &openapi.Schema{Type: "integer"}, inferSchema(map[string]int{"1": 1, "-20": 1}))
+	assert.Equal(t, &openapi.Schema{Type: "number"}, inferSchema(map[string]int{"1": 1, "0.5": 1}))
+	assert.Equal(t, &openapi.Schema{Type: "boolean"}, inferSchema(map[string]int{"true": 1, "false": 1}))
+	assert.Equal(t, &openapi.Schema{Type: "string", Format: "uuid"}, inferSchema(map[string]int{"0b00a8c0-f528-ca63-5b26-685f02030303": 1}))
+	assert.Equal(t, &openapi.Schema{Type: "string", Enum: []any{"asc", "desc"}}, inferSchema(map[string]int{"asc": 6, "desc": 4}))
+	assert.Equal(t, &openapi.Schema{Type: "string"}, inferSchema(map[string]int{"asc": 1, "desc": 1}), "too few occurrences for enum")
+	assert.Equal(t, &openapi.Schema{Type: "string"}, inferSchema(nil))
+}
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/haijima/stool/internal/log"
+)
+
+// PositionKeys are the query keys recognized as the position of a page
+var PositionKeys = []string{"page", "offset", "cursor", "last_id"}
+
+// PageSizeKeys are the query keys recognized as the size of a page
+var PageSizeKeys = []string{"limit", "per_page"}
+
+// ProfilePagination collects the paging query parameters of each endpoint and the walks of consecutive pages in each session.
+// The log entries are expected to be in chronological order.
+func (p *ParamProfiler) ProfilePagination(reader *log.LTSVReader, opt SessionOption) (*Pagination, error) {
+	result := &Pagination{
+		Endpoints: make([]string, 0),
+		Count:     make(map[string]int),
+		Paging:    make(map[string]*EndpointPagination),
+	}
+	last := make(map[string]*pageRequest) // session and endpoint -> the previous request
+	sessionizer := NewSessionizer(opt)
+
+	var entry log.LogEntry
+	endpointsMap := make(map[string]interface{})
+	for reader.Read() {
+		_, err := reader.Parse(&entry)
+		if err != nil {
+			if err == log.Filtered {
+				continue
+			}
+			return nil, err
+		}
+
+		key := fmt.Sprintf("%s %s", entry.Method, entry.Uri)
+		result.Count[key] += 1
+		_, _, query := log.ParseReq(entry.Req)
+		req := newPageRequest(query)
+
+		ep, ok := result.Paging[key]
+		if !ok {
+			ep = &EndpointPagination{Position: map[string]map[string]int{}, PageSize: map[string]int{}, walks: map[string]int{}}
+			result.Paging[key] = ep
+		}
+		if req.positionKey != "" {
+			endpointsMap[key] = nil
+			ep.Requests += 1
+			if _, ok := ep.Position[req.positionKey]; !ok {
+				ep.Position[req.positionKey] = map[string]int{}
+			}
+			ep.Position[req.positionKey][req.position] += 1
+		}
+		if req.sizeKey != "" {
+			endpointsMap[key] = nil
+			ep.PageSize[req.size] += 1
+		}
+
+		session, _ := sessionizer.Session(entry)
+		if session == "" { // requests without a uid or out of any session can not be attributed to a walk
+			continue
+		}
+		lastKey := session + " " + key
+		prev, ok := last[lastKey]
+		last[lastKey] = req
+		if req.positionKey == "" && req.sizeKey == "" { // may be the first page, but not a walk by itself
+			ep.endWalk(lastKey)
+			continue
+		}
+		if ok && req.follows(prev) {
+			if _, walking := ep.walks[lastKey]; walking {
+				ep.walks[lastKey] += 1
+			} else {
+				ep.walks[lastKey] = 2 // the first page was requested without paging
+			}
+		} else {
+			ep.endWalk(lastKey)
+			ep.walks[lastKey] = 1
+		}
+	}
+
+	for key, ep := range result.Paging {
+		if _, ok := endpointsMap[key]; !ok {
+			delete(result.Paging, key)
+			continue
+		}
+		for k := range ep.walks {
+			ep.endWalk(k)
+		}
+		slices.Sort(ep.Walks)
+	}
+	result.Endpoints = sortedEndpoints(endpointsMap)
+	return result, nil
+}
+
+// Pagination is the paging query parameters of endpoints having any of PositionKeys or PageSizeKeys
+type Pagination struct {
+	Endpoints []string
+	Count     map[string]int
+	Paging    map[string]*EndpointPagination
+}
+
+type EndpointPagination struct {
+	Requests int                       // requests with any of PositionKeys
+	Position map[string]map[string]int // position key -> value -> count
+	PageSize map[string]int            // value of PageSizeKeys -> count
+	Walks    []int                     // the number of consecutive pages requested in a session in ascending order
+	walks    map[string]int            // walks in progress
+}
+
+func (e *EndpointPagination) endWalk(k string) {
+	if n, ok := e.walks[k]; ok {
+		e.Walks = append(e.Walks, n)
+		delete(e.walks, k)
+	}
+}
+
+// MultiPageWalks returns the number of walks over 2 or more pages
+func (e *EndpointPagination) MultiPageWalks() int {
+	i, _ := slices.BinarySearch(e.Walks, 2)
+	return len(e.Walks) - i
+}
+
+type pageRequest struct {
+	positionKey string
+	position    string
+	sizeKey     string
+	size        string
+}
+
+func newPageRequest(query string) *pageRequest {
+	req := &pageRequest{}
+	for _, q := range strings.Split(query, "&") {
+		k, v, _ := strings.Cut(q, "=")
+		if slices.Contains(PositionKeys, k) {
+			req.positionKey, req.position = k, v
+		} else if slices.Contains(PageSizeKeys, k) {
+			req.sizeKey, req.size = k, v
+		}
+	}
+	return req
+}
+
+// follows reports whether r requests the next page of prev
+func (r *pageRequest) follows(prev *pageRequest) bool {
+	switch r.positionKey {
+	case "page":
+		p, err1 := strconv.Atoi(r.position)
+		pp, err2 := strconv.Atoi(prev.position)
+		if prev.positionKey == "" { // the first page is often requested without page
+			pp, err2 = 1, nil
+		}
+		return err1 == nil && err2 == nil && p == pp+1
+	case "offset":
+		o, err1 := strconv.Atoi(r.position)
+		po, err2 := strconv.Atoi(prev.position)
+		if prev.positionKey == "" {
+			po, err2 = 0, nil
+		}
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if size, err := strconv.Atoi(cmp.Or(prev.size, r.size)); err == nil {
+			return o == po+size
+		}
+		return o > po
+	case "cursor", "last_id":
+		return prev.positionKey == "" || (prev.positionKey == r.positionKey && prev.position != r.position)
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamProfiler_ProfilePagination(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /api/items?page=2&limit=20 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:02 +0900\treq:GET /api/items?page=1&limit=20 HTTP/2.0\tstatus:200\tuidset:uid=B\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:03 +0900\treq:GET /api/items?page=3&limit=20 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:04 +0900\treq:GET /api/items?page=3&limit=50 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=B\n" + // skips a page
+		"time:01/Jan/2023:12:00:05 +0900\treq:GET /api/users HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n") // no paging
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{})
+	require.NoError(t, err)
+
+	pagination, err := NewParamProfiler().ProfilePagination(logReader, SessionOption{StartOnUidGot: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET /api/items"}, pagination.Endpoints)
+	ep := pagination.Paging["GET /api/items"]
+	assert.Equal(t, 4, ep.Requests)
+	assert.Equal(t, map[string]map[string]int{"page": {"1": 1, "2": 1, "3": 2}}, ep.Position)
+	assert.Equal(t, map[string]int{"20": 3, "50": 1}, ep.PageSize)
+	assert.Equal(t, []int{1, 1, 3}, ep.Walks)
+	assert.Equal(t, 1, ep.MultiPageWalks())
+}
+
+func TestParamProfiler_ProfilePagination_NonPagingRequests(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:uid=B\tuidgot:-\n" + // never pages
+		"time:01/Jan/2023:12:00:02 +0900\treq:GET /api/items?page=2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:03 +0900\treq:GET /api/items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // back to the top
+		"time:01/Jan/2023:12:00:04 +0900\treq:GET /api/items?page=nan HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n")
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{})
+	require.NoError(t, err)
+
+	pagination, err := NewParamProfiler().ProfilePagination(logReader, SessionOption{StartOnUidGot: true})
+
+	require.NoError(t, err)
+	ep := pagination.Paging["GET /api/items"]
+	assert.Equal(t, 5, pagination.Count["GET /api/items"])
+	assert.Equal(t, 2, ep.Requests)
+	assert.Equal(t, map[string]map[string]int{"page": {"2": 1, "nan": 1}}, ep.Position)
+	assert.Equal(t, []int{1, 2}, ep.Walks)
+}
+
+func TestParamProfiler_ProfilePagination_Session(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/items?page=1 HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /api/items?page=2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:10:00 +0900\treq:GET /api/items?page=3 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // another session after idle
+		"time:01/Jan/2023:12:10:01 +0900\treq:GET /api/items?page=1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=B\n" + // first seen via uidgot
+		"time:01/Jan/2023:12:10:02 +0900\treq:GET /api/items?page=2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=B\n")
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{})
+	require.NoError(t, err)
+
+	pagination, err := NewParamProfiler().ProfilePagination(logReader, SessionOption{IdleTimeout: time.Minute})
+
+	require.NoError(t, err)
+	ep := pagination.Paging["GET /api/items"]
+	assert.Equal(t, 5, ep.Requests)
+	assert.Equal(t, []int{1, 2}, ep.Walks)
+}
+
+func TestParamProfiler_ProfilePagination_NoSession(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/items?page=1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /api/items?page=2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=B\n") // another client out of any session
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{})
+	require.NoError(t, err)
+
+	pagination, err := NewParamProfiler().ProfilePagination(logReader, SessionOption{})
+
+	require.NoError(t, err)
+	ep := pagination.Paging["GET /api/items"]
+	assert.Equal(t, 2, ep.Requests)
+	assert.Empty(t, ep.Walks)
+}
+
+func Test_pageRequest_follows(t *testing.T) {
+	tests := []struct {
+		name        string
+		prev, query string
+		want        bool
+	}{
+		{name: "next page", prev: "page=1", query: "page=2", want: true},
+		{name: "first page without page", prev: "", query: "page=2", want: true},
+		{name: "same page", prev: "page=2", query: "page=2", want: false},
+		{name: "previous page", prev: "page=2", query: "page=1", want: false},
+		{name: "next offset", prev: "offset=0&limit=10", query: "offset=10&limit=10", want: true},
+		{name: "skipped offset", prev: "offset=0&limit=10", query: "offset=20&limit=10", want: false},
+		{name: "offset without limit", prev: "offset=0", query: "offset=25", want: true},
+		{name: "first cursor", prev: "", query: "cursor=abc", want: true},
+		{name: "next cursor", prev: "cursor=abc", query: "cursor=def", want: true},
+		{name: "same cursor", prev: "cursor=abc", query: "cursor=abc", want: false},
+		{name: "next last_id", prev: "last_id=10", query: "last_id=20", want: true},
+		{name: "no paging", prev: "page=1", query: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newPageRequest(tt.query).follows(newPageRequest(tt.prev)))
+		})
+	}
+}
@@ -22,6 +22,8 @@ func (p *ParamProfiler) Profile(reader *log.LTSVReader) (*Param, error) {
 		Count:                 make(map[string]int),
 		Path:                  make(map[string][]map[string]int),
 		PathName:              make(map[string][]string),
+		Matched:               make(map[string]bool),
+		Status:                make(map[string]map[int]int),
 		QueryKey:              make(map[string]map[string]int),
 		QueryKeyCombination:   make(map[string]map[string]int),
 		QueryValue:            make(map[string]map[string]map[string]int),
@@ -42,6 +44,11 @@ func (p *ParamProfiler) Profile(reader *log.LTSVReader) (*Param, error) {
 		_, uri, query := log.ParseReq(entry.Req)
 		key := fmt.Sprintf("%s %s", entry.Method, entry.Uri)
 		endpointsMap[key] = nil
+		param.Matched[key] = entry.MatchedGroup != nil
+		if _, ok := param.Status[key]; !ok {
+			param.Status[key] = map[int]int{}
+		}
+		param.Status[key][entry.Status] += 1
 
 		// Path param
 		if entry.MatchedGroup != nil {
@@ -90,8 +97,14 @@ func (p *ParamProfiler) Profile(reader *log.LTSVReader) (*Param, error) {
 		param.Count[key] += 1
 	}
 
-	param.Endpoints = maps.Keys(endpointsMap)
-	slices.SortFunc(param.Endpoints, func(i, j string) int {
+	param.Endpoints = sortedEndpoints(endpointsMap)
+	return param, nil
+}
+
+// sortedEndpoints returns the keys of "METHOD URI" sorted by URI and method
+func sortedEndpoints(endpointsMap map[string]interface{}) []string {
+	endpoints := maps.Keys(endpointsMap)
+	slices.SortFunc(endpoints, func(i, j string) int {
 		ii := strings.Split(i, " ")
 		jj := strings.Split(j, " ")
 		if ii[1] != jj[1] {
@@ -99,7 +112,7 @@ func (p *ParamProfiler) Profile(reader *log.LTSVReader) (*Param, error) {
 		}
 		return strings.Compare(ii[0], jj[0])
 	})
-	return param, nil
+	return endpoints
 }
 
 type Param struct {
@@ -107,6 +120,8 @@ type Param struct {
 	Count                 map[string]int
 	Path                  map[string][]map[string]int
 	PathName              map[string][]string
+	Matched               map[string]bool // whether the endpoint is grouped by matching groups
+	Status                map[string]map[int]int
 	QueryKey              map[string]map[string]int
 	QueryKeyCombination   map[string]map[string]int
 	QueryValue            map[string]map[string]map[string]int
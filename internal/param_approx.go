@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/haijima/stool/internal/log"
+	"github.com/haijima/stool/internal/sketch"
+)
+
+// hllPrecision is the precision of HyperLogLog. 2^14 registers use 16KiB and the standard error is 0.81%.
+const hllPrecision = 14
+
+// ProfileApprox profiles the parameters like Profile, but keeps only capacity values per parameter
+// so that the memory usage does not grow with the number of distinct values.
+func (p *ParamProfiler) ProfileApprox(reader *log.LTSVReader, capacity int) (*ApproxParam, error) {
+	param := &ApproxParam{
+		Endpoints:             make([]string, 0),
+		Count:                 make(map[string]int),
+		Path:                  make(map[string][]*ApproxValues),
+		PathName:              make(map[string][]string),
+		QueryKey:              make(map[string]map[string]int),
+		QueryKeyCombination:   make(map[string]*ApproxValues),
+		QueryValue:            make(map[string]map[string]*ApproxValues),
+		QueryValueCombination: make(map[string]*ApproxValues),
+	}
+
+	var entry log.LogEntry
+	endpointsMap := make(map[string]interface{})
+	for reader.Read() {
+		_, err := reader.Parse(&entry)
+		if err != nil {
+			if err == log.Filtered {
+				continue
+			}
+			return nil, err
+		}
+
+		_, uri, query := log.ParseReq(entry.Req)
+		key := fmt.Sprintf("%s %s", entry.Method, entry.Uri)
+		endpointsMap[key] = nil
+
+		// Path param
+		if entry.MatchedGroup != nil {
+			subMatches := entry.MatchedGroup.FindStringSubmatch(uri)
+			if len(subMatches) > 1 { // this entry URI has path param
+				if _, ok := param.Path[key]; !ok {
+					param.Path[key] = make([]*ApproxValues, len(subMatches)-1)
+					for i := range param.Path[key] {
+						param.Path[key][i] = NewApproxValues(capacity)
+					}
+					param.PathName[key] = entry.MatchedGroup.SubexpNames()[1:]
+				}
+				for i, v := range subMatches[1:] {
+					param.Path[key][i].Add(v)
+				}
+			}
+		}
+
+		// Query param
+		if query != "" {
+			if _, ok := param.QueryValue[key]; !ok {
+				param.QueryKey[key] = map[string]int{}
+				param.QueryKeyCombination[key] = NewApproxValues(capacity)
+				param.QueryValue[key] = map[string]*ApproxValues{}
+				param.QueryValueCombination[key] = NewApproxValues(capacity)
+			}
+			qs := strings.Split(query, "&")
+			slices.Sort(qs)
+			qks := make([]string, 0)
+			for _, q := range qs {
+				if k, v, ok := strings.Cut(q, "="); ok {
+					param.QueryKey[key][k] += 1
+					if _, ok := param.QueryValue[key][k]; !ok {
+						param.QueryValue[key][k] = NewApproxValues(capacity)
+					}
+					param.QueryValue[key][k].Add(v)
+					qks = append(qks, k)
+				}
+			}
+			param.QueryKeyCombination[key].Add(strings.Join(qks, "&"))
+			param.QueryValueCombination[key].Add(strings.Join(qs, "&"))
+		}
+
+		param.Count[key] += 1
+	}
+
+	param.Endpoints = sortedEndpoints(endpointsMap)
+	return param, nil
+}
+
+// ApproxParam is the approximate version of Param.
+// The values of each parameter are summarized by ApproxValues instead of the exact counts.
+type ApproxParam struct {
+	Endpoints             []string
+	Count                 map[string]int
+	Path                  map[string][]*ApproxValues
+	PathName              map[string][]string
+	QueryKey              map[string]map[string]int
+	QueryKeyCombination   map[string]*ApproxValues
+	QueryValue            map[string]map[string]*ApproxValues
+	QueryValueCombination map[string]*ApproxValues
+}
+
+// ApproxValues tracks the frequent values and the cardinality of a parameter in constant memory
+type ApproxValues struct {
+	TopK        *sketch.SpaceSaving
+	Cardinality *sketch.HyperLogLog
+}
+
+func NewApproxValues(capacity int) *ApproxValues {
+	return &ApproxValues{
+		TopK:        sketch.NewSpaceSaving(capacity),
+		Cardinality: sketch.NewHyperLogLog(hllPrecision),
+	}
+}
+
+func (a *ApproxValues) Add(v string) {
+	a.TopK.Add(v)
+	a.Cardinality.Add(v)
+}
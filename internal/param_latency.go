@@ -0,0 +1,194 @@
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/haijima/stool/internal/log"
+)
+
+// ProfileLatency collects the response times of each parameter value.
+// Log entries without the reqtime field are skipped.
+func (p *ParamProfiler) ProfileLatency(reader *log.LTSVReader) (*ParamLatency, error) {
+	param := &ParamLatency{
+		Endpoints: make([]string, 0),
+		Count:     make(map[string]int),
+		Total:     make(map[string]float64),
+		Path:      make(map[string][]map[string]*LatencySamples),
+		PathName:  make(map[string][]string),
+		Query:     make(map[string]map[string]map[string]*LatencySamples),
+	}
+	rng := rand.New(rand.NewPCG(1, 2)) // fixed seed so that the same log gives the same result
+
+	var entry log.LogEntry
+	endpointsMap := make(map[string]interface{})
+	for reader.Read() {
+		_, err := reader.Parse(&entry)
+		if err != nil {
+			if err == log.Filtered {
+				continue
+			}
+			return nil, err
+		}
+		if !entry.HasReqTime {
+			continue
+		}
+
+		_, uri, query := log.ParseReq(entry.Req)
+		key := fmt.Sprintf("%s %s", entry.Method, entry.Uri)
+		endpointsMap[key] = nil
+		sec := entry.ReqTime.Seconds()
+
+		// Path param
+		if entry.MatchedGroup != nil {
+			subMatches := entry.MatchedGroup.FindStringSubmatch(uri)
+			if len(subMatches) > 1 { // this entry URI has path param
+				if _, ok := param.Path[key]; !ok {
+					param.Path[key] = make([]map[string]*LatencySamples, len(subMatches)-1)
+					for i := range param.Path[key] {
+						param.Path[key][i] = map[string]*LatencySamples{}
+					}
+					param.PathName[key] = entry.MatchedGroup.SubexpNames()[1:]
+				}
+				for i, v := range subMatches[1:] {
+					addLatency(param.Path[key][i], v, sec, rng)
+				}
+			}
+		}
+
+		// Query param
+		if query != "" {
+			if _, ok := param.Query[key]; !ok {
+				param.Query[key] = map[string]map[string]*LatencySamples{}
+			}
+			for _, q := range strings.Split(query, "&") {
+				if k, v, ok := strings.Cut(q, "="); ok {
+					if _, ok := param.Query[key][k]; !ok {
+						param.Query[key][k] = map[string]*LatencySamples{}
+					}
+					addLatency(param.Query[key][k], v, sec, rng)
+				}
+			}
+		}
+
+		param.Count[key] += 1
+		param.Total[key] += sec
+	}
+
+	param.Endpoints = sortedEndpoints(endpointsMap)
+	return param, nil
+}
+
+// ParamLatency holds the response times in seconds of each parameter value
+type ParamLatency struct {
+	Endpoints []string
+	Count     map[string]int
+	Total     map[string]float64
+	Path      map[string][]map[string]*LatencySamples
+	PathName  map[string][]string
+	Query     map[string]map[string]map[string]*LatencySamples
+}
+
+// latencySampleSize is the number of response times kept per parameter value to estimate p95
+const latencySampleSize = 1000
+
+// LatencySamples summarizes the response times of a parameter value in seconds in constant memory.
+// The count, the mean and the sum of squared deviations are exact (Welford, 1962),
+// and p95 is estimated from a uniform random sample of at most latencySampleSize response times (Vitter, 1985).
+type LatencySamples struct {
+	Count   int
+	Mean    float64
+	M2      float64 // sum of squared deviations from the mean
+	samples []float64
+}
+
+// Add adds the response time. rng chooses the samples to keep after latencySampleSize ones.
+func (l *LatencySamples) Add(sec float64, rng *rand.Rand) {
+	l.Count++
+	delta := sec - l.Mean
+	l.Mean += delta / float64(l.Count)
+	l.M2 += delta * (sec - l.Mean)
+	if len(l.samples) < latencySampleSize {
+		l.samples = append(l.samples, sec)
+	} else if i := rng.IntN(l.Count); i < latencySampleSize {
+		l.samples[i] = sec
+	}
+}
+
+// Total returns the sum of the response times
+func (l *LatencySamples) Total() float64 {
+	return l.Mean * float64(l.Count)
+}
+
+// P95 returns the 95th percentile of the sampled response times
+func (l *LatencySamples) P95() float64 {
+	sorted := slices.Clone(l.samples)
+	slices.Sort(sorted)
+	return sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+}
+
+func addLatency(values map[string]*LatencySamples, v string, sec float64, rng *rand.Rand) {
+	if _, ok := values[v]; !ok {
+		values[v] = &LatencySamples{}
+	}
+	values[v].Add(sec, rng)
+}
+
+// LatencyStat is the response time statistics of a parameter value in seconds
+type LatencyStat struct {
+	Value string
+	Count int
+	Mean  float64
+	P95   float64
+	Total float64
+}
+
+// NewLatencyStats returns the statistics of each value in descending order of the total time consumed
+func NewLatencyStats(values map[string]*LatencySamples) []LatencyStat {
+	stats := make([]LatencyStat, 0, len(values))
+	for v, l := range values {
+		stats = append(stats, LatencyStat{
+			Value: v,
+			Count: l.Count,
+			Mean:  l.Mean,
+			P95:   l.P95(),
+			Total: l.Total(),
+		})
+	}
+	slices.SortFunc(stats, func(a, b LatencyStat) int {
+		if a.Total != b.Total {
+			return cmp.Compare(b.Total, a.Total)
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	return stats
+}
+
+// ExplainedVariance returns the ratio of the variance of response times explained by the parameter value (η², eta squared).
+// It is the between-value sum of squares divided by the total sum of squares, and is 0 if all response times are the same.
+func ExplainedVariance(values map[string]*LatencySamples) float64 {
+	var n int
+	var sum float64
+	for _, l := range values {
+		sum += l.Total()
+		n += l.Count
+	}
+	if n == 0 {
+		return 0
+	}
+	mean := sum / float64(n)
+
+	var between, within float64
+	for _, l := range values {
+		between += float64(l.Count) * (l.Mean - mean) * (l.Mean - mean)
+		within += l.M2
+	}
+	if between+within == 0 {
+		return 0
+	}
+	return between / (between + within)
+}
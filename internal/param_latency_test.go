@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLatencyStats(t *testing.T) {
+	stats := NewLatencyStats(latencySamples(map[string][]float64{
+		"1": {0.1, 0.3, 0.2},
+		"2": {1.0},
+		"3": {0.5, 0.5},
+	}))
+
+	assert.Len(t, stats, 3)
+	assert.Equal(t, LatencyStat{Value: "2", Count: 1, Mean: 1.0, P95: 1.0, Total: 1.0}, stats[0])
+	assert.Equal(t, LatencyStat{Value: "3", Count: 2, Mean: 0.5, P95: 0.5, Total: 1.0}, stats[1]) // ties are ordered by value
+	assert.Equal(t, "1", stats[2].Value)
+	assert.Equal(t, 3, stats[2].Count)
+	assert.InDelta(t, 0.2, stats[2].Mean, 1e-9)
+	assert.Equal(t, 0.3, stats[2].P95)
+	assert.InDelta(t, 0.6, stats[2].Total, 1e-9)
+}
+
+func TestExplainedVariance(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string][]float64
+		want   float64
+	}{
+		{name: "empty", values: map[string][]float64{}, want: 0},
+		{name: "constant", values: map[string][]float64{"1": {1, 1}, "2": {1}}, want: 0},
+		{name: "fully explained", values: map[string][]float64{"1": {1, 1}, "2": {3, 3}}, want: 1},
+		{name: "not explained", values: map[string][]float64{"1": {1, 3}, "2": {1, 3}}, want: 0},
+		{name: "partially explained", values: map[string][]float64{"1": {0, 2}, "2": {2, 4}}, want: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, ExplainedVariance(latencySamples(tt.values)), 1e-9)
+		})
+	}
+}
+
+func TestLatencySamples_Add(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	l := &LatencySamples{}
+	for i := range 10 * latencySampleSize {
+		l.Add(float64(i%100)/100, rng)
+	}
+
+	assert.Equal(t, 10*latencySampleSize, l.Count)
+	assert.Len(t, l.samples, latencySampleSize)
+	assert.InDelta(t, 0.495, l.Mean, 1e-9)
+	assert.InDelta(t, 0.495*float64(10*latencySampleSize), l.Total(), 1e-6)
+	assert.InDelta(t, 0.95, l.P95(), 0.02)
+}
+
+func latencySamples(values map[string][]float64) map[string]*LatencySamples {
+	rng := rand.New(rand.NewPCG(1, 2))
+	result := make(map[string]*LatencySamples, len(values))
+	for v, times := range values {
+		result[v] = &LatencySamples{}
+		for _, sec := range times {
+			result[v].Add(sec, rng)
+		}
+	}
+	return result
+}
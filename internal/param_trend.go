@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+	"golang.org/x/exp/maps"
+)
+
+// ProfileTrend counts the values of the parameter at each interval (in seconds).
+// The parameter is given as ":name" or ":N" (1-based position) for a path parameter, "?key" for a query parameter,
+// or a bare name for either of them.
+func (p *ParamProfiler) ProfileTrend(reader *log.LTSVReader, param string, interval int) (*ParamTrend, error) {
+	result := &ParamTrend{
+		Interval:  interval,
+		Endpoints: make([]string, 0),
+		Total:     make(map[string]int),
+		Sum:       make(map[string]map[string]int),
+		counts:    make(map[string]map[string]map[int]int),
+	}
+	var startTime time.Time
+
+	var entry log.LogEntry
+	endpointsMap := make(map[string]interface{})
+	for reader.Read() {
+		_, err := reader.Parse(&entry)
+		if err != nil {
+			if err == log.Filtered {
+				continue
+			}
+			return nil, err
+		}
+
+		if startTime.IsZero() {
+			startTime = entry.Time
+		}
+		t := max(int(entry.Time.Sub(startTime).Seconds())/interval, 0) // a line before the first one is in the first interval
+		result.Step = max(result.Step, t+1)
+
+		value, ok := paramValue(entry, param)
+		if !ok {
+			continue
+		}
+		key := fmt.Sprintf("%s %s", entry.Method, entry.Uri)
+		if _, ok := result.Sum[key]; !ok {
+			endpointsMap[key] = nil
+			result.Sum[key] = map[string]int{}
+			result.counts[key] = map[string]map[int]int{}
+		}
+		if _, ok := result.counts[key][value]; !ok {
+			result.counts[key][value] = map[int]int{}
+		}
+		result.counts[key][value][t] += 1
+		result.Sum[key][value] += 1
+		result.Total[key] += 1
+	}
+
+	result.Endpoints = sortedEndpoints(endpointsMap)
+	return result, nil
+}
+
+// ParamTrend is the counts of the values of a parameter over time
+type ParamTrend struct {
+	Interval  int
+	Step      int
+	Endpoints []string
+	Total     map[string]int                    // requests with the parameter of each endpoint
+	Sum       map[string]map[string]int         // endpoint -> value -> count
+	counts    map[string]map[string]map[int]int // endpoint -> value -> step -> count
+}
+
+// TopValues returns up to n values of the endpoint in descending order of the counts
+func (t *ParamTrend) TopValues(endpoint string, n int) []string {
+	values := maps.Keys(t.Sum[endpoint])
+	slices.SortFunc(values, func(a, b string) int {
+		if t.Sum[endpoint][a] != t.Sum[endpoint][b] {
+			return cmp.Compare(t.Sum[endpoint][b], t.Sum[endpoint][a])
+		}
+		return strings.Compare(a, b)
+	})
+	if len(values) > n {
+		values = values[:n]
+	}
+	return values
+}
+
+// Counts returns the counts of the value at each interval
+func (t *ParamTrend) Counts(endpoint, value string) []int {
+	counts := make([]int, t.Step)
+	for i, c := range t.counts[endpoint][value] {
+		counts[i] = c
+	}
+	return counts
+}
+
+// paramValue returns the value of the parameter of the entry
+func paramValue(entry log.LogEntry, param string) (string, bool) {
+	_, path, query := log.ParseReq(entry.Req)
+	name, inPath, inQuery := param, true, true
+	if n, ok := strings.CutPrefix(param, ":"); ok {
+		name, inQuery = n, false
+	} else if n, ok := strings.CutPrefix(param, "?"); ok {
+		name, inPath = n, false
+	}
+
+	if inPath && entry.MatchedGroup != nil {
+		subMatches := entry.MatchedGroup.FindStringSubmatch(path)
+		for i, n := range entry.MatchedGroup.SubexpNames() {
+			if i > 0 && (n == name || (!inQuery && strconv.Itoa(i) == name)) {
+				return subMatches[i], true
+			}
+		}
+	}
+	if inQuery && query != "" {
+		for _, q := range strings.Split(query, "&") {
+			if k, v, ok := strings.Cut(q, "="); ok && k == name {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/haijima/stool/internal/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamProfiler_ProfileTrend(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /users/1 HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /users/2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:06 +0900\treq:GET /users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:07 +0900\treq:GET /users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:12 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n")
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{MatchingGroups: []string{"^/users/(?P<id>[^/]+)$"}})
+	require.NoError(t, err)
+
+	trend, err := NewParamProfiler().ProfileTrend(logReader, ":id", 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, trend.Step)
+	assert.Equal(t, []string{"GET ^/users/(?P<id>[^/]+)$"}, trend.Endpoints)
+	assert.Equal(t, 4, trend.Total["GET ^/users/(?P<id>[^/]+)$"])
+	assert.Equal(t, []string{"1", "2"}, trend.TopValues("GET ^/users/(?P<id>[^/]+)$", 5))
+	assert.Equal(t, []string{"1"}, trend.TopValues("GET ^/users/(?P<id>[^/]+)$", 1))
+	assert.Equal(t, []int{1, 2, 0}, trend.Counts("GET ^/users/(?P<id>[^/]+)$", "1"))
+	assert.Equal(t, []int{1, 0, 0}, trend.Counts("GET ^/users/(?P<id>[^/]+)$", "2"))
+}
+
+func TestParamProfiler_ProfileTrend_OutOfOrder(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:10 +0900\treq:GET /users/1 HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:00 +0900\treq:GET /users/2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" + // earlier than the first line
+		"time:01/Jan/2023:12:00:16 +0900\treq:GET /users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n")
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{MatchingGroups: []string{"^/users/(?P<id>[^/]+)$"}})
+	require.NoError(t, err)
+
+	trend, err := NewParamProfiler().ProfileTrend(logReader, ":id", 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, trend.Step)
+	assert.Equal(t, []int{1, 1}, trend.Counts("GET ^/users/(?P<id>[^/]+)$", "1"))
+	assert.Equal(t, []int{1, 0}, trend.Counts("GET ^/users/(?P<id>[^/]+)$", "2"))
+}
+
+func Test_paramValue(t *testing.T) {
+	named := regexp.MustCompile("^/users/(?P<id>[^/]+)$")
+	unnamed := regexp.MustCompile("^/users/([^/]+)$")
+	tests := []struct {
+		name  string
+		entry log.LogEntry
+		param string
+		want  string
+		ok    bool
+	}{
+		{name: "named path param", entry: log.LogEntry{Req: "GET /users/1?id=2 HTTP/2.0", MatchedGroup: named}, param: ":id", want: "1", ok: true},
+		{name: "path param by position", entry: log.LogEntry{Req: "GET /users/1 HTTP/2.0", MatchedGroup: unnamed}, param: ":1", want: "1", ok: true},
+		{name: "query param", entry: log.LogEntry{Req: "GET /users/1?id=2 HTTP/2.0", MatchedGroup: named}, param: "?id", want: "2", ok: true},
+		{name: "bare name prefers path param", entry: log.LogEntry{Req: "GET /users/1?id=2 HTTP/2.0", MatchedGroup: named}, param: "id", want: "1", ok: true},
+		{name: "bare name in query", entry: log.LogEntry{Req: "GET /items?id=2 HTTP/2.0"}, param: "id", want: "2", ok: true},
+		{name: "not found", entry: log.LogEntry{Req: "GET /items?page=2 HTTP/2.0"}, param: "id", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := paramValue(tt.entry, tt.param)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package internal
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type ParamType int
+
+const (
+	ParamTypeText ParamType = iota
+	ParamTypeInteger
+	ParamTypeFloat
+	ParamTypeBoolean
+	ParamTypeUUID
+	ParamTypeDate
+	ParamTypeEnum
+)
+
+func (t ParamType) String() string {
+	switch t {
+	case ParamTypeInteger:
+		return "integer"
+	case ParamTypeFloat:
+		return "float"
+	case ParamTypeBoolean:
+		return "boolean"
+	case ParamTypeUUID:
+		return "uuid"
+	case ParamTypeDate:
+		return "date"
+	case ParamTypeEnum:
+		return "enum"
+	default:
+		return "text"
+	}
+}
+
+func (t ParamType) IsNumeric() bool {
+	return t == ParamTypeInteger || t == ParamTypeFloat
+}
+
+// MarshalText is used to output the type by its name in JSON and YAML
+func (t ParamType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// maxEnumCardinality is the maximum number of distinct values to infer an enum
+const maxEnumCardinality = 10
+
+// minEnumOccurrence is the minimum average occurrence of each value to infer an enum
+const minEnumOccurrence = 5
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var dateFormats = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+
+// InferParamType infers the type of the parameter from the observed values and their counts.
+// A type is inferred only when all the values satisfy it. Low-cardinality values are inferred as an enum.
+func InferParamType(values map[string]int) ParamType {
+	if len(values) == 0 {
+		return ParamTypeText
+	}
+	isInteger, isFloat, isBoolean, isUUID, isDate := true, true, true, true, true
+	total := 0
+	for v, c := range values {
+		total += c
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			isInteger = false
+		}
+		if _, ok := parseFiniteFloat(v); !ok {
+			isFloat = false
+		}
+		if v != "true" && v != "false" {
+			isBoolean = false
+		}
+		if !uuidRegexp.MatchString(v) {
+			isUUID = false
+		}
+		if isDate && !isDateValue(v) {
+			isDate = false
+		}
+	}
+
+	switch {
+	case isInteger:
+		return ParamTypeInteger
+	case isFloat:
+		return ParamTypeFloat
+	case isBoolean:
+		return ParamTypeBoolean
+	case isUUID:
+		return ParamTypeUUID
+	case isDate:
+		return ParamTypeDate
+	case len(values) <= maxEnumCardinality && total >= len(values)*minEnumOccurrence:
+		return ParamTypeEnum
+	default:
+		return ParamTypeText
+	}
+}
+
+// parseFiniteFloat parses the value as a float. Values like "inf", "nan" and "1e999" are not regarded as numeric.
+func parseFiniteFloat(v string) (float64, bool) {
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, false
+	}
+	return n, true
+}
+
+func isDateValue(v string) bool {
+	if unescaped, err := url.QueryUnescape(v); err == nil {
+		v = unescaped
+	}
+	for _, f := range dateFormats {
+		if _, err := time.Parse(f, v); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Distribution is the distribution of numeric parameter values
+type Distribution struct {
+	Min       float64  `json:"min" yaml:"min"`
+	Max       float64  `json:"max" yaml:"max"`
+	Mean      float64  `json:"mean" yaml:"mean"`
+	P50       float64  `json:"p50" yaml:"p50"`
+	P90       float64  `json:"p90" yaml:"p90"`
+	P95       float64  `json:"p95" yaml:"p95"`
+	P99       float64  `json:"p99" yaml:"p99"`
+	Histogram []Bucket `json:"histogram" yaml:"histogram"`
+}
+
+// Bucket is a bucket of the histogram that counts values in [Lower, Upper). The last bucket includes Upper.
+type Bucket struct {
+	Lower float64 `json:"lower" yaml:"lower"`
+	Upper float64 `json:"upper" yaml:"upper"`
+	Count int     `json:"count" yaml:"count"`
+}
+
+// NewDistribution calculates the distribution of the numeric values weighted by their counts.
+// Buckets of integer values have integer widths. Non-finite values like "inf" and "nan" are skipped.
+// It returns nil if any value is not numeric.
+func NewDistribution(values map[string]int, buckets int, integer bool) *Distribution {
+	type numCount struct {
+		num   float64
+		count int
+	}
+	nums := make([]numCount, 0, len(values))
+	total := 0
+	for v, c := range values {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return nil
+		}
+		if math.IsInf(n, 0) || math.IsNaN(n) {
+			continue
+		}
+		nums = append(nums, numCount{n, c})
+		total += c
+	}
+	if total == 0 || buckets <= 0 {
+		return nil
+	}
+	slices.SortFunc(nums, func(a, b numCount) int { return cmp.Compare(a.num, b.num) })
+
+	// the mean is updated incrementally in a stable order, as the sum of large values may overflow
+	mean, cum := 0.0, 0
+	for _, n := range nums {
+		cum += n.count
+		w := float64(n.count) / float64(cum)
+		mean = mean*(1-w) + n.num*w
+	}
+
+	quantile := func(q float64) float64 {
+		rank := int(math.Ceil(q * float64(total)))
+		cum := 0
+		for _, n := range nums {
+			cum += n.count
+			if cum >= rank {
+				return n.num
+			}
+		}
+		return nums[len(nums)-1].num
+	}
+
+	d := &Distribution{
+		Min:  nums[0].num,
+		Max:  nums[len(nums)-1].num,
+		Mean: mean,
+		P50:  quantile(0.5),
+		P90:  quantile(0.9),
+		P95:  quantile(0.95),
+		P99:  quantile(0.99),
+	}
+
+	width := (d.Max - d.Min) / float64(buckets)
+	if math.IsInf(width, 0) {
+		// the range overflows, so all the values are counted in a single bucket
+		width = 0
+		buckets = 1
+	} else if integer {
+		width = math.Max(1, math.Ceil((d.Max-d.Min+1)/float64(buckets)))
+		buckets = int(math.Ceil((d.Max - d.Min + 1) / width))
+	} else if width == 0 {
+		buckets = 1
+	}
+	d.Histogram = make([]Bucket, buckets)
+	for i := range d.Histogram {
+		d.Histogram[i].Lower = d.Min + width*float64(i)
+		d.Histogram[i].Upper = d.Min + width*float64(i+1)
+	}
+	for _, n := range nums {
+		i := buckets - 1
+		if width > 0 {
+			i = min(int((n.num-d.Min)/width), buckets-1)
+		}
+		d.Histogram[i].Count += n.count
+	}
+	return d
+}
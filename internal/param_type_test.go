@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferParamType(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]int
+		want   ParamType
+	}{
+		{name: "empty", values: map[string]int{}, want: ParamTypeText},
+		{name: "integer", values: map[string]int{"1": 1, "-20": 3}, want: ParamTypeInteger},
+		{name: "float", values: map[string]int{"1": 1, "0.5": 1}, want: ParamTypeFloat},
+		{name: "boolean", values: map[string]int{"true": 1, "false": 1}, want: ParamTypeBoolean},
+		{name: "uuid", values: map[string]int{"0b00a8c0-f528-ca63-5b26-685f02030303": 1}, want: ParamTypeUUID},
+		{name: "date", values: map[string]int{"2024-01-01": 1, "2024-01-01T10%3A00%3A00Z": 1, "2024-01-01 10:00:00": 1}, want: ParamTypeDate},
+		{name: "enum", values: map[string]int{"asc": 6, "desc": 4}, want: ParamTypeEnum},
+		{name: "too few occurrences for enum", values: map[string]int{"asc": 1, "desc": 1}, want: ParamTypeText},
+		{name: "text", values: map[string]int{"a": 1, "b": 1, "1": 1}, want: ParamTypeText},
+		{name: "inf", values: map[string]int{"1": 1, "inf": 1}, want: ParamTypeText},
+		{name: "nan", values: map[string]int{"1.5": 1, "NaN": 1}, want: ParamTypeText},
+		{name: "overflow", values: map[string]int{"1": 1, "1e999": 1}, want: ParamTypeText},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, InferParamType(tt.values))
+		})
+	}
+}
+
+func TestNewDistribution(t *testing.T) {
+	d := NewDistribution(map[string]int{"0": 5, "10": 3, "25": 1, "99": 1}, 4, true)
+
+	assert.Equal(t, 0.0, d.Min)
+	assert.Equal(t, 99.0, d.Max)
+	assert.Equal(t, 15.4, d.Mean)
+	assert.Equal(t, 0.0, d.P50)
+	assert.Equal(t, 25.0, d.P90)
+	assert.Equal(t, 99.0, d.P95)
+	assert.Equal(t, 99.0, d.P99)
+	assert.Equal(t, []Bucket{{0, 25, 8}, {25, 50, 1}, {50, 75, 0}, {75, 100, 1}}, d.Histogram)
+}
+
+func TestNewDistribution_Float(t *testing.T) {
+	d := NewDistribution(map[string]int{"0.5": 1, "1.5": 1}, 2, false)
+
+	assert.Equal(t, []Bucket{{0.5, 1.0, 1}, {1.0, 1.5, 1}}, d.Histogram)
+}
+
+func TestNewDistribution_NotNumeric(t *testing.T) {
+	assert.Nil(t, NewDistribution(map[string]int{"a": 1}, 10, false))
+}
+
+func TestNewDistribution_NonFinite(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]int
+		integer bool
+		want    *Distribution
+	}{
+		{name: "inf", values: map[string]int{"1": 1, "inf": 1, "-Inf": 1}, integer: false, want: &Distribution{Min: 1, Max: 1, Mean: 1, P50: 1, P90: 1, P95: 1, P99: 1, Histogram: []Bucket{{1, 1, 1}}}},
+		{name: "nan", values: map[string]int{"1": 1, "nan": 1}, integer: true, want: &Distribution{Min: 1, Max: 1, Mean: 1, P50: 1, P90: 1, P95: 1, P99: 1, Histogram: []Bucket{{1, 2, 1}}}},
+		{name: "overflow", values: map[string]int{"1": 1, "1e999": 1}, integer: true, want: &Distribution{Min: 1, Max: 1, Mean: 1, P50: 1, P90: 1, P95: 1, P99: 1, Histogram: []Bucket{{1, 2, 1}}}},
+		{name: "only non-finite", values: map[string]int{"inf": 1, "nan": 1}, integer: false, want: nil},
+		{name: "huge range", values: map[string]int{"-1e308": 1, "1e308": 1}, integer: true, want: &Distribution{Min: -1e308, Max: 1e308, Mean: 0, P50: -1e308, P90: 1e308, P95: 1e308, P99: 1e308, Histogram: []Bucket{{-1e308, -1e308, 2}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewDistribution(tt.values, 10, tt.integer))
+		})
+	}
+}
+
+func TestNewDistribution_HugeSum(t *testing.T) {
+	d := NewDistribution(map[string]int{"1e308": 3, "1.5e308": 1}, 10, false)
+
+	assert.Equal(t, 1.125e308, d.Mean)
+	_, err := json.Marshal(d)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,61 @@
+package pattern
+
+import "slices"
+
+// EditDistance returns the Levenshtein distance between the sequences of endpoints
+func EditDistance(a, b []string) int {
+	d, _ := align(len(a), len(b), func(i, j int) bool { return a[i] == b[j] })
+	return d
+}
+
+// Similarity returns the similarity of the sequences of endpoints from 0 to 1 based on EditDistance.
+// Two empty sequences are regarded as the same.
+func Similarity(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	return 1 - float64(EditDistance(a, b))/float64(max(len(a), len(b)))
+}
+
+// align returns the Levenshtein distance between the sequences of the lengths n and m whose elements are compared by eq,
+// and the pairs of the aligned indices in order. The index of a deleted or an inserted element is paired with -1.
+func align(n, m int, eq func(i, j int) bool) (int, [][2]int) {
+	d := make([][]int, n+1)
+	for i := range d {
+		d[i] = make([]int, m+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if eq(i-1, j-1) {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+		}
+	}
+
+	pairs := make([][2]int, 0, max(n, m))
+	i, j := n, m
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && eq(i-1, j-1) && d[i][j] == d[i-1][j-1]:
+			pairs = append(pairs, [2]int{i - 1, j - 1})
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && d[i][j] == d[i-1][j-1]+1:
+			pairs = append(pairs, [2]int{i - 1, j - 1})
+			i, j = i-1, j-1
+		case i > 0 && d[i][j] == d[i-1][j]+1:
+			pairs = append(pairs, [2]int{i - 1, -1})
+			i--
+		default:
+			pairs = append(pairs, [2]int{-1, j - 1})
+			j--
+		}
+	}
+	slices.Reverse(pairs)
+	return d[n][m], pairs
+}
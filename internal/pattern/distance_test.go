@@ -0,0 +1,36 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        []string
+		b        []string
+		distance int
+	}{
+		{name: "empty", a: []string{}, b: []string{}, distance: 0},
+		{name: "same", a: []string{"A", "B", "C"}, b: []string{"A", "B", "C"}, distance: 0},
+		{name: "deletion", a: []string{"A", "B", "C"}, b: []string{"A", "C"}, distance: 1},
+		{name: "insertion", a: []string{"A", "C"}, b: []string{"A", "B", "C"}, distance: 1},
+		{name: "substitution", a: []string{"A", "B", "C"}, b: []string{"A", "D", "C"}, distance: 1},
+		{name: "to empty", a: []string{"A", "B"}, b: []string{}, distance: 2},
+		{name: "different", a: []string{"A", "B"}, b: []string{"C", "D", "E"}, distance: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.distance, EditDistance(tt.a, tt.b))
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity([]string{}, []string{}))
+	assert.Equal(t, 1.0, Similarity([]string{"A", "B"}, []string{"A", "B"}))
+	assert.Equal(t, 0.75, Similarity([]string{"A", "B", "C", "D"}, []string{"A", "C", "D"}))
+	assert.Equal(t, 0.0, Similarity([]string{"A"}, []string{"B"}))
+}
@@ -0,0 +1,141 @@
+package pattern
+
+// maxMatchSteps limits the backtracking of the match not to take too long on ambiguous patterns
+const maxMatchSteps = 1_000_000
+
+// Groups returns the repetitions like "(B -> C)*", the optionals like "B?" and the alternations like "(B | C)" in the pattern in pre-order.
+// The node itself is not included because it is regarded as the root.
+func (n *Node) Groups() []*Node {
+	groups := make([]*Node, 0)
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for i := range n.children {
+			if c := &n.children[i]; !c.IsLeaf() {
+				if c.kind != Sequence {
+					groups = append(groups, c)
+				}
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return groups
+}
+
+// Iterations matches the sequence of endpoints with the pattern regarding the node as the root,
+// and returns the observed counts of each group in the order of Groups.
+// The count is the number of the iterations of a repetition, 1 if an optional is taken or 0 if skipped,
+// and the index of the taken alternative of an alternation.
+// A group has as many counts as it is entered. It returns false if the sequence doesn't match the pattern.
+func (n *Node) Iterations(seq []string) ([][]int, bool) {
+	m, ok := n.match(seq)
+	if !ok {
+		return nil, false
+	}
+	result := make([][]int, len(m.groups))
+	for i := range result {
+		result[i] = make([]int, 0)
+	}
+	for _, r := range m.records {
+		result[r.id] = append(result[r.id], r.count)
+	}
+	return result, true
+}
+
+// LeafIndices matches the sequence of endpoints with the pattern regarding the node as the root,
+// and returns the index of the leaf which each endpoint matches in the order of Flatten.
+// It returns false if the sequence doesn't match the pattern.
+func (n *Node) LeafIndices(seq []string) ([]int, bool) {
+	m, ok := n.match(seq)
+	if !ok {
+		return nil, false
+	}
+	return m.leaves, true
+}
+
+type matchRecord struct{ id, count int }
+
+type matcher struct {
+	groups  []*Node
+	ids     map[*Node]int // index of the repeat group
+	bases   map[*Node]int // index of the first leaf of the node
+	records []matchRecord
+	leaves  []int
+	steps   int
+}
+
+func (n *Node) match(seq []string) (*matcher, bool) {
+	m := &matcher{groups: n.Groups(), ids: map[*Node]int{}, bases: map[*Node]int{}, leaves: make([]int, 0, len(seq))}
+	for i, g := range m.groups {
+		m.ids[g] = i
+	}
+	var walk func(n *Node, base int)
+	walk = func(n *Node, base int) {
+		m.bases[n] = base
+		for i := range n.children {
+			walk(&n.children[i], base)
+			base += n.children[i].leaves
+		}
+	}
+	walk(n, 0)
+
+	var matchNodes func(nodes []Node, i int, k func(int) bool) bool
+	matchNodes = func(nodes []Node, i int, k func(int) bool) bool {
+		if m.steps++; m.steps > maxMatchSteps {
+			return false
+		}
+		if len(nodes) == 0 {
+			return k(i)
+		}
+		node := &nodes[0]
+		if node.IsLeaf() {
+			if i >= len(seq) || seq[i] != node.value {
+				return false
+			}
+			m.leaves = append(m.leaves, m.bases[node])
+			if matchNodes(nodes[1:], i+1, k) {
+				return true
+			}
+			m.leaves = m.leaves[:len(m.leaves)-1]
+			return false
+		}
+
+		// record the count of the group and match the rest
+		next := func(count int) func(j int) bool {
+			return func(j int) bool {
+				m.records = append(m.records, matchRecord{m.ids[node], count})
+				if matchNodes(nodes[1:], j, k) {
+					return true
+				}
+				m.records = m.records[:len(m.records)-1]
+				return false
+			}
+		}
+		switch node.kind {
+		case Optional:
+			// take the optional if possible and backtrack if the rest doesn't match
+			return matchNodes(node.children, i, next(1)) || next(0)(i)
+		case Alternation:
+			for index := range node.children {
+				if matchNodes(node.children[index].children, i, next(index)) {
+					return true
+				}
+			}
+			return false
+		}
+
+		// match the group greedily and backtrack if the rest doesn't match
+		var loop func(i, count int) bool
+		loop = func(i, count int) bool {
+			return matchNodes(node.children, i, func(j int) bool {
+				return (j > i && loop(j, count+1)) || next(count)(j)
+			})
+		}
+		return loop(i, 1)
+	}
+
+	if !matchNodes(n.children, 0, func(i int) bool { return i == len(seq) }) {
+		return nil, false
+	}
+	return m, true
+}
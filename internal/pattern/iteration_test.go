@@ -0,0 +1,78 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNode_Iterations(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		seq     []string
+		want    [][]int
+		ok      bool
+	}{
+		{name: "no group", pattern: "A -> B", seq: []string{"A", "B"}, want: [][]int{}, ok: true},
+		{name: "single group", pattern: "A -> (B)*", seq: []string{"A", "B", "B", "B"}, want: [][]int{{3}}, ok: true},
+		{name: "nested group", pattern: "A -> ((B)* -> C)* -> D", seq: []string{"A", "B", "C", "B", "B", "C", "D"}, want: [][]int{{2}, {1, 2}}, ok: true},
+		{name: "backtrack", pattern: "(A -> B)* -> A -> C", seq: []string{"A", "B", "A", "B", "A", "C"}, want: [][]int{{2}}, ok: true},
+		{name: "optional taken", pattern: "A -> B? -> C", seq: []string{"A", "B", "C"}, want: [][]int{{1}}, ok: true},
+		{name: "optional skipped", pattern: "A -> B? -> C", seq: []string{"A", "C"}, want: [][]int{{0}}, ok: true},
+		{name: "alternation", pattern: "A -> (B | C -> D) -> E", seq: []string{"A", "C", "D", "E"}, want: [][]int{{1}}, ok: true},
+		{name: "choices in group", pattern: "(A -> B? -> (C | D))*", seq: []string{"A", "B", "C", "A", "D"}, want: [][]int{{2}, {1, 0}, {0, 1}}, ok: true},
+		{name: "optional backtrack", pattern: "A -> (B)? -> B", seq: []string{"A", "B"}, want: [][]int{{0}}, ok: true},
+		{name: "unmatched alternation", pattern: "A -> (B | C)", seq: []string{"A", "D"}, ok: false},
+		{name: "unmatched", pattern: "A -> (B)*", seq: []string{"A", "C"}, ok: false},
+		{name: "too short", pattern: "A -> B", seq: []string{"A"}, ok: false},
+		{name: "too long", pattern: "A -> B", seq: []string{"A", "B", "B"}, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Parse(tt.pattern)
+			assert.NoError(t, err)
+
+			got, ok := n.Iterations(tt.seq)
+
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNode_Iterations_appended(t *testing.T) {
+	seq := []string{"A", "B", "C", "B", "C", "D", "B", "C", "C", "D", "E"}
+	root := NewRoot()
+	for _, s := range seq {
+		root.Append(s)
+	}
+
+	got, ok := root.Iterations(seq)
+
+	assert.True(t, ok)
+	assert.Equal(t, len(root.Groups()), len(got))
+}
+
+func TestNode_LeafIndices(t *testing.T) {
+	n, err := Parse("A -> ((B)* -> C)* -> D")
+	assert.NoError(t, err)
+
+	got, ok := n.LeafIndices([]string{"A", "B", "C", "B", "B", "C", "D"})
+
+	assert.True(t, ok)
+	assert.Equal(t, []int{0, 1, 2, 1, 1, 2, 3}, got)
+
+	n, err = Parse("A -> (B | C -> D) -> E? -> F")
+	assert.NoError(t, err)
+
+	got, ok = n.LeafIndices([]string{"A", "C", "D", "F"})
+
+	assert.True(t, ok)
+	assert.Equal(t, []int{0, 2, 3, 5}, got)
+
+	_, ok = n.LeafIndices([]string{"A", "D", "D"})
+	assert.False(t, ok)
+}
@@ -1,11 +1,24 @@
 package pattern
 
 import (
+	"strings"
+
 	"golang.org/x/exp/slices"
 )
 
+// Kind is the kind of a node which has children
+type Kind int
+
+const (
+	Repeat      Kind = iota // repetition like "(B -> C)*"
+	Optional                // optional steps like "B?" or "(B -> C)?"
+	Alternation             // alternatives like "(B | C -> D)". each child is a Sequence of an alternative
+	Sequence                // steps of an alternative
+)
+
 type Node struct {
 	value    string
+	kind     Kind
 	children []Node
 	leaves   int
 }
@@ -22,10 +35,29 @@ func NewLeaf(value string) *Node {
 	return &Node{value: value, leaves: 1}
 }
 
+// NewOptional returns a node whose children may be skipped
+func NewOptional(children []Node) *Node {
+	return &Node{kind: Optional, children: children, leaves: leaves(children)}
+}
+
+// NewAlternation returns a node which takes one of the alternatives
+func NewAlternation(alternatives [][]Node) *Node {
+	children := make([]Node, 0, len(alternatives))
+	for _, a := range alternatives {
+		children = append(children, Node{kind: Sequence, children: a, leaves: leaves(a)})
+	}
+	return &Node{kind: Alternation, children: children, leaves: leaves(children)}
+}
+
 func (n *Node) Value() string {
 	return n.value
 }
 
+// Kind returns the kind of the node. It is meaningless for a leaf.
+func (n *Node) Kind() Kind {
+	return n.kind
+}
+
 func (n *Node) Children() []Node {
 	return n.children
 }
@@ -62,7 +94,7 @@ func (n *Node) IsLeaf() bool {
 
 func (n *Node) String(root bool) string {
 	if n.IsLeaf() {
-		return n.value
+		return escapeLeaf(n.value)
 	}
 
 	str := ""
@@ -74,6 +106,22 @@ func (n *Node) String(root bool) string {
 		}
 	}
 
+	switch n.kind {
+	case Optional:
+		if len(n.children) == 1 && (n.children[0].IsLeaf() || n.children[0].kind == Alternation) {
+			return str + "?"
+		}
+		return "(" + str + ")?"
+	case Alternation:
+		alternatives := make([]string, 0, len(n.children))
+		for i := range n.children {
+			alternatives = append(alternatives, n.children[i].String(false))
+		}
+		return "(" + strings.Join(alternatives, " | ") + ")"
+	case Sequence:
+		return str
+	}
+
 	if root {
 		return str
 	}
@@ -131,8 +179,10 @@ func (n *Node) append(nodes []Node) bool {
 	return false
 }
 
+// Merge merges the nodes whose flattened endpoints are the same into repetitions.
+// Nodes with optional steps or alternatives are not merged. Use MergeOptional to merge different nodes.
 func Merge(src, dest []Node) (*Node, bool) {
-	if !flatCompare(src, dest) {
+	if hasChoice(src) || hasChoice(dest) || !flatCompare(src, dest) {
 		return nil, false
 	}
 
@@ -181,6 +231,16 @@ func merge(src, dest []Node) []Node {
 	return nil
 }
 
+// hasChoice returns whether the nodes have optional steps or alternatives
+func hasChoice(nodes []Node) bool {
+	for i := range nodes {
+		if !nodes[i].IsLeaf() && (nodes[i].kind != Repeat || hasChoice(nodes[i].children)) {
+			return true
+		}
+	}
+	return false
+}
+
 func leaves(nodes []Node) int {
 	r := 0
 	for i := range nodes {
@@ -0,0 +1,118 @@
+package pattern
+
+import "strings"
+
+// MergeOptional merges the different sequences of nodes into a root node by aligning them with the edit distance.
+// Nodes inserted or deleted in either sequence become optional like "B?", and substituted nodes become alternatives
+// like "(B | C)". Aligned repetitions are merged by Merge, and optionals and alternations of src are kept
+// if they cover the aligned nodes of dest.
+func MergeOptional(src, dest []Node) *Node {
+	a, b := tokens(src), tokens(dest)
+	eq := func(i, j int) bool { return a[i] == b[j] || covers(src[i], b[j]) }
+	_, pairs := align(len(src), len(dest), eq)
+
+	children := make([]Node, 0, len(src))
+	var gapSrc, gapDest []Node
+	flush := func() {
+		switch {
+		case len(gapSrc) == 0 && len(gapDest) == 0:
+			return
+		case len(gapDest) == 0:
+			children = append(children, *optional(gapSrc))
+		case len(gapSrc) == 0:
+			children = append(children, *optional(gapDest))
+		default:
+			children = append(children, *alternate(gapSrc, gapDest))
+		}
+		gapSrc, gapDest = nil, nil
+	}
+	for _, p := range pairs {
+		if p[0] >= 0 && p[1] >= 0 && eq(p[0], p[1]) {
+			flush()
+			s, d := src[p[0]], dest[p[1]]
+			if s.IsLeaf() && d.IsLeaf() {
+				children = append(children, s)
+			} else if m, ok := Merge([]Node{s}, []Node{d}); ok && !m.IsLeaf() {
+				children = append(children, *m)
+			} else {
+				children = append(children, s)
+			}
+			continue
+		}
+		if p[0] >= 0 {
+			gapSrc = append(gapSrc, src[p[0]])
+		}
+		if p[1] >= 0 {
+			gapDest = append(gapDest, dest[p[1]])
+		}
+	}
+	flush()
+	return NewNode(children)
+}
+
+// tokens returns the strings to align the nodes. A leaf and a repetition are compared by their flattened endpoints
+// so that "B" and "(B)*" are aligned.
+func tokens(nodes []Node) []string {
+	result := make([]string, 0, len(nodes))
+	for i := range nodes {
+		if nodes[i].IsLeaf() || nodes[i].kind == Repeat {
+			result = append(result, strings.Join(Flatten(nodes[i:i+1], make([]string, nodes[i].leaves)), separator))
+		} else {
+			result = append(result, nodes[i].String(false))
+		}
+	}
+	return result
+}
+
+// covers returns whether the optional or the alternation takes the single node of the token
+func covers(n Node, token string) bool {
+	if n.IsLeaf() {
+		return false
+	}
+	switch n.kind {
+	case Optional:
+		return len(n.children) == 1 && (tokens(n.children)[0] == token || covers(n.children[0], token))
+	case Alternation:
+		for _, a := range n.children {
+			if len(a.children) == 1 && (tokens(a.children)[0] == token || covers(a.children[0], token)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// optional returns the optional of the nodes. An optional is returned as it is.
+func optional(nodes []Node) *Node {
+	if len(nodes) == 1 && !nodes[0].IsLeaf() && nodes[0].kind == Optional {
+		return &nodes[0]
+	}
+	return NewOptional(nodes)
+}
+
+// alternate returns the alternation of the sequences of nodes.
+// An alternative is added to the existing alternation and the same sequence as the existing one is not added.
+func alternate(src, dest []Node) *Node {
+	if len(src) == 1 && !src[0].IsLeaf() {
+		switch src[0].kind {
+		case Optional:
+			if sequenceString(src[0].children) == sequenceString(dest) {
+				return &src[0]
+			}
+		case Alternation:
+			alternatives := make([][]Node, 0, src[0].Degree()+1)
+			for _, a := range src[0].children {
+				if sequenceString(a.children) == sequenceString(dest) {
+					return &src[0]
+				}
+				alternatives = append(alternatives, a.children)
+			}
+			return NewAlternation(append(alternatives, dest))
+		}
+	}
+	return NewAlternation([][]Node{src, dest})
+}
+
+func sequenceString(nodes []Node) string {
+	return NewNode(nodes).String(true)
+}
@@ -0,0 +1,66 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeOptional(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		dest string
+		want string
+	}{
+		{name: "same", src: "A -> (B)* -> C", dest: "A -> (B)* -> C", want: "A -> (B)* -> C"},
+		{name: "deletion", src: "A -> B -> C", dest: "A -> C", want: "A -> B? -> C"},
+		{name: "insertion", src: "A -> C", dest: "A -> B -> C", want: "A -> B? -> C"},
+		{name: "deletions", src: "A -> B -> (C)* -> D", dest: "A -> D", want: "A -> (B -> (C)*)? -> D"},
+		{name: "substitution", src: "A -> B -> D", dest: "A -> C -> D", want: "A -> (B | C) -> D"},
+		{name: "repetition", src: "A -> (B)* -> C", dest: "A -> B -> C", want: "A -> (B)* -> C"},
+		{name: "existing optional", src: "A -> B? -> C", dest: "A -> C", want: "A -> B? -> C"},
+		{name: "taken optional", src: "A -> B? -> C", dest: "A -> B -> C", want: "A -> B? -> C"},
+		{name: "existing alternative", src: "A -> (B | C) -> D", dest: "A -> C -> D", want: "A -> (B | C) -> D"},
+		{name: "new alternative", src: "A -> (B | C) -> D", dest: "A -> E -> D", want: "A -> (B | C | E) -> D"},
+		{name: "skipped alternation", src: "A -> (B | C) -> D", dest: "A -> D", want: "A -> (B | C)? -> D"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := Parse(tt.src)
+			assert.NoError(t, err)
+			dest, err := Parse(tt.dest)
+			assert.NoError(t, err)
+
+			got := MergeOptional(src.Children(), dest.Children())
+
+			assert.Equal(t, tt.want, got.String(true))
+			parsed, err := Parse(got.String(true))
+			assert.NoError(t, err)
+			assert.Equal(t, got.Leaves(), parsed.Leaves())
+		})
+	}
+}
+
+func TestNode_String_choices(t *testing.T) {
+	n := NewNode([]Node{
+		*NewLeaf("A"),
+		*NewOptional([]Node{*NewLeaf("B")}),
+		*NewAlternation([][]Node{{*NewLeaf("C")}, {*NewLeaf("D"), *NewLeaf("E")}}),
+		*NewOptional([]Node{*NewLeaf("F"), *NewLeaf("G")}),
+	})
+
+	assert.Equal(t, "A -> B? -> (C | D -> E) -> (F -> G)?", n.String(true))
+	assert.Equal(t, 7, n.Leaves())
+	assert.Equal(t, []string{"A", "B", "C", "D", "E", "F", "G"}, Flatten(n.Children(), make([]string, n.Leaves())))
+	assert.Equal(t, 3, len(n.Groups()))
+}
+
+func TestMerge_choices(t *testing.T) {
+	n, err := Parse("A -> B? -> C")
+	assert.NoError(t, err)
+
+	_, ok := Merge([]Node{*n}, []Node{*n})
+
+	assert.False(t, ok)
+}
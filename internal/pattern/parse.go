@@ -0,0 +1,157 @@
+package pattern
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	separator            = " -> "
+	alternationSeparator = " | "
+)
+
+// Parse reads the string form of a root node like "A -> (B -> C)* -> D? -> (E | F)", which is returned by String(true), into a Node
+func Parse(s string) (*Node, error) {
+	if s == "" {
+		root := NewRoot()
+		return &root, nil
+	}
+	children, rest, err := parseSequence(s, 0)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("unexpected %q at %d in %q", rest, len(s)-len(rest), s)
+	}
+	return NewNode(children), nil
+}
+
+// parseSequence reads nodes joined by the separator in the depth of groups and returns them with the unread string
+func parseSequence(s string, depth int) ([]Node, string, error) {
+	nodes := make([]Node, 0)
+	for {
+		if strings.HasPrefix(s, "(") {
+			alternatives, rest, err := parseAlternatives(s[1:], depth+1)
+			if err != nil {
+				return nil, "", err
+			}
+			if !strings.HasPrefix(rest, ")") {
+				return nil, "", fmt.Errorf("%q is expected but: %q", ")", rest)
+			}
+			rest = rest[1:]
+			children := alternatives[0]
+			if len(alternatives) > 1 {
+				children = []Node{*NewAlternation(alternatives)}
+			}
+			switch {
+			case strings.HasPrefix(rest, "*"):
+				nodes = append(nodes, *NewNode(children))
+				rest = rest[1:]
+			case strings.HasPrefix(rest, "?"):
+				nodes = append(nodes, *NewOptional(children))
+				rest = rest[1:]
+			case len(alternatives) > 1:
+				nodes = append(nodes, children[0])
+			default:
+				return nil, "", fmt.Errorf("a group should be a repetition, an optional or alternatives but: %q", s)
+			}
+			s = rest
+		} else {
+			end := len(s)
+			if i := strings.Index(s, separator); i >= 0 {
+				end = i
+			}
+			if depth > 0 {
+				if i := strings.Index(s[:end], alternationSeparator); i >= 0 {
+					end = i
+				}
+				end = groupClose(s[:end], depth)
+			}
+			value, optional := unescapeLeaf(s[:end])
+			if value == "" {
+				return nil, "", fmt.Errorf("endpoint is expected but: %q", s)
+			}
+			if optional {
+				nodes = append(nodes, *NewOptional([]Node{*NewLeaf(value)}))
+			} else {
+				nodes = append(nodes, *NewLeaf(value))
+			}
+			s = s[end:]
+		}
+
+		if !strings.HasPrefix(s, separator) {
+			return nodes, s, nil
+		}
+		s = s[len(separator):]
+	}
+}
+
+// parseAlternatives reads sequences joined by the alternation separator and returns them with the unread string
+func parseAlternatives(s string, depth int) ([][]Node, string, error) {
+	alternatives := make([][]Node, 0)
+	for {
+		nodes, rest, err := parseSequence(s, depth)
+		if err != nil {
+			return nil, "", err
+		}
+		alternatives = append(alternatives, nodes)
+		if !strings.HasPrefix(rest, alternationSeparator) {
+			return alternatives, rest, nil
+		}
+		s = rest[len(alternationSeparator):]
+	}
+}
+
+// groupClose returns the end of the endpoint followed by the ends of at most depth groups like "C)*)?".
+// Endpoints may have parentheses like regular expressions of matching groups, so the first one which can close the groups is taken.
+func groupClose(s string, depth int) int {
+	for i := range s {
+		if s[i] != ')' {
+			continue
+		}
+		count := 0
+		rest := s[i:]
+		for strings.HasPrefix(rest, ")") && count <= depth {
+			count++
+			rest = rest[1:]
+			if strings.HasPrefix(rest, "*") || strings.HasPrefix(rest, "?") {
+				rest = rest[1:]
+			}
+		}
+		if rest == "" && count <= depth {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// escapeLeaf escapes "?" and "\" at the end of the endpoint with "\", so that a literal "?" is not read as an optional
+func escapeLeaf(value string) string {
+	i := len(strings.TrimRight(value, `?\`))
+	var sb strings.Builder
+	sb.WriteString(value[:i])
+	for _, c := range value[i:] {
+		sb.WriteByte('\\')
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+// unescapeLeaf reads the endpoint escaped by escapeLeaf and whether it is marked as an optional by the last unescaped "?"
+func unescapeLeaf(s string) (string, bool) {
+	i := len(strings.TrimRight(s, `?\`))
+	value, tail := []byte(s[:i]), s[i:]
+	optional := false
+	for j := 0; j < len(tail); j++ {
+		switch {
+		case tail[j] == '\\' && j+1 < len(tail):
+			j++
+			value = append(value, tail[j])
+		case tail[j] == '?' && j == len(tail)-1:
+			optional = true
+		default:
+			value = append(value, tail[j])
+		}
+	}
+	return string(value), optional
+}
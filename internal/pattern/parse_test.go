@@ -0,0 +1,90 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		leaves int
+		degree int
+	}{
+		{name: "empty", input: "", leaves: 0, degree: 0},
+		{name: "single", input: "GET /", leaves: 1, degree: 1},
+		{name: "sequence", input: "POST /login -> GET /items -> GET /items/:id", leaves: 3, degree: 3},
+		{name: "repetition", input: "A -> (B)*", leaves: 2, degree: 2},
+		{name: "nest", input: "A -> ((B -> C)* -> D)* -> E", leaves: 5, degree: 3},
+		{name: "group at the end", input: "A -> (B -> (C -> (D -> E)*)*)*", leaves: 5, degree: 2},
+		{name: "consecutive groups", input: "((A)* -> (B)* -> (C)*)* -> D", leaves: 4, degree: 2},
+		{name: "optional", input: "A -> B? -> C", leaves: 3, degree: 3},
+		{name: "optional group", input: "A -> (B -> (C)*)? -> D", leaves: 4, degree: 3},
+		{name: "alternation", input: "A -> (B | C -> D) -> E", leaves: 5, degree: 3},
+		{name: "optional alternation", input: "A -> (B | (C)*)? -> E", leaves: 4, degree: 3},
+		{name: "choices in group", input: "(A -> B? -> (C | D))* -> E", leaves: 5, degree: 2},
+		{name: "parentheses in endpoint", input: "GET ^/users/([0-9]+)$ -> (GET ^/items/(?P<id>[0-9]+))*", leaves: 2, degree: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Parse(tt.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.input, n.String(true))
+			assert.Equal(t, tt.leaves, n.Leaves())
+			assert.Equal(t, tt.degree, n.Degree())
+		})
+	}
+}
+
+func TestParse_roundTrip(t *testing.T) {
+	root := NewRoot()
+	for _, endpoint := range []string{"A", "B", "C", "C", "A", "B", "B", "C", "B", "C", "D"} {
+		root.Append(endpoint)
+	}
+
+	n, err := Parse(root.String(true))
+
+	assert.NoError(t, err)
+	assert.Equal(t, root.String(true), n.String(true))
+	assert.Equal(t, root.Leaves(), n.Leaves())
+	assert.NoError(t, validateElem(*n))
+	m, ok := Merge([]Node{root}, []Node{*n})
+	assert.True(t, ok)
+	assert.Equal(t, root.String(true), m.String(true))
+}
+
+func TestParse_questionMarkInEndpoint(t *testing.T) {
+	tests := []struct {
+		name   string
+		node   *Node
+		output string
+	}{
+		{name: "literal", node: NewNode([]Node{*NewLeaf("GET /search?")}), output: `GET /search\?`},
+		{name: "optional literal", node: NewNode([]Node{*NewOptional([]Node{*NewLeaf("GET /search?")})}), output: `GET /search\??`},
+		{name: "backslash", node: NewNode([]Node{*NewLeaf(`GET /a\`), *NewOptional([]Node{*NewLeaf(`GET /b\`)})}), output: `GET /a\\ -> GET /b\\?`},
+		{name: "in group", node: NewNode([]Node{*NewLeaf("A"), *NewNode([]Node{*NewLeaf("GET /search?")})}), output: `A -> (GET /search\?)*`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.output, tt.node.String(true))
+
+			n, err := Parse(tt.node.String(true))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.node, n)
+		})
+	}
+}
+
+func TestParse_error(t *testing.T) {
+	for _, input := range []string{"A -> ", "A -> (B -> C", "(B)* -> ", "()*", "(B)", "(B | C", "A -> ?"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := Parse(input)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"cmp"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+)
+
+type Replayer struct {
+}
+
+func NewReplayer() *Replayer {
+	return &Replayer{}
+}
+
+// ReplayOption is the option to replay the logged requests
+type ReplayOption struct {
+	BaseURL     string
+	Methods     []string     // methods to replay. the other requests are skipped
+	Speed       float64      // multiplier of the original timing. 1 keeps the original timing and 0 doesn't wait
+	Concurrency int          // number of the workers replaying the users, which is the maximum number of requests in flight
+	Client      *http.Client // should not follow redirects so that the status of a 3xx response is compared with the logged one
+}
+
+// Replay is the result of the replay
+type Replay struct {
+	Endpoints   []string
+	Count       map[string]int
+	Success     map[string]int             // number of the responses with 2xx or 3xx status
+	StatusMatch map[string]int             // number of the responses with the same status as the logged one
+	Errors      map[string]int             // number of the requests failed without response
+	Latencies   map[string][]time.Duration // sorted latencies of the requests
+	Users       int                        // number of the users. each request without uid is counted as a user
+	Skipped     int                        // number of the requests skipped by the method
+	Elapsed     time.Duration
+}
+
+type replayRequest struct {
+	key    string
+	method string
+	uri    string // path and query
+	status int
+	offset time.Duration // time from the first request of the log
+}
+
+type replayResult struct {
+	key     string
+	status  int
+	matched bool
+	err     error
+	latency time.Duration
+}
+
+// Replay re-issues the logged requests to opt.BaseURL.
+// Requests of each user are issued in the logged order, and requests without uid are issued independently.
+// opt.Concurrency workers take the users in order of their first requests, so a user waits for a free worker
+// when more users than the workers are active at the same time.
+func (r *Replayer) Replay(reader *log.LTSVReader, opt ReplayOption) (*Replay, error) {
+	users, skipped, err := readReplayRequests(reader, opt.Methods)
+	if err != nil {
+		return nil, err
+	}
+	client := opt.Client
+	if client == nil {
+		client = &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	}
+	baseURL := strings.TrimSuffix(opt.BaseURL, "/")
+
+	slices.SortStableFunc(users, func(a, b []replayRequest) int { return cmp.Compare(a[0].offset, b[0].offset) })
+	queue := make(chan []replayRequest)
+	results := make(chan replayResult)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for range max(1, opt.Concurrency) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for reqs := range queue {
+				for _, req := range reqs {
+					if opt.Speed > 0 {
+						time.Sleep(time.Until(start.Add(time.Duration(float64(req.offset) / opt.Speed))))
+					}
+					results <- doReplayRequest(client, baseURL, req)
+				}
+			}
+		}()
+	}
+	go func() {
+		for _, reqs := range users {
+			queue <- reqs
+		}
+		close(queue)
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	result := &Replay{
+		Count:       map[string]int{},
+		Success:     map[string]int{},
+		StatusMatch: map[string]int{},
+		Errors:      map[string]int{},
+		Latencies:   map[string][]time.Duration{},
+		Users:       len(users),
+		Skipped:     skipped,
+	}
+	endpointsMap := map[string]interface{}{}
+	for res := range results {
+		endpointsMap[res.key] = nil
+		result.Count[res.key]++
+		if res.err != nil {
+			result.Errors[res.key]++
+			continue
+		}
+		if res.status < 400 {
+			result.Success[res.key]++
+		}
+		if res.matched {
+			result.StatusMatch[res.key]++
+		}
+		result.Latencies[res.key] = append(result.Latencies[res.key], res.latency)
+	}
+	result.Elapsed = time.Since(start)
+	result.Endpoints = sortedEndpoints(endpointsMap)
+	for _, latencies := range result.Latencies {
+		slices.Sort(latencies)
+	}
+	return result, nil
+}
+
+// readReplayRequests reads the requests of the methods grouped by the user
+func readReplayRequests(reader *log.LTSVReader, methods []string) ([][]replayRequest, int, error) {
+	users := make([][]replayRequest, 0)
+	userIndex := map[string]int{}
+	skipped := 0
+	var startTime time.Time
+	var entry log.LogEntry
+	for reader.Read() {
+		_, err := reader.Parse(&entry)
+		if err != nil {
+			if err == log.Filtered {
+				continue
+			}
+			return nil, 0, err
+		}
+		if !slices.Contains(methods, entry.Method) {
+			skipped++
+			continue
+		}
+		if startTime.IsZero() {
+			startTime = entry.Time
+		}
+
+		// use the request target as it is not to change the encoding
+		_, uri, _ := strings.Cut(entry.Req, " ")
+		uri, _, _ = strings.Cut(uri, " ")
+		req := replayRequest{key: entry.Key(), method: entry.Method, uri: uri, status: entry.Status, offset: entry.Time.Sub(startTime)}
+		if i, ok := userIndex[entry.Uid]; ok && entry.Uid != "" {
+			users[i] = append(users[i], req)
+		} else {
+			userIndex[entry.Uid] = len(users)
+			users = append(users, []replayRequest{req})
+		}
+	}
+	return users, skipped, nil
+}
+
+func doReplayRequest(client *http.Client, baseURL string, req replayRequest) replayResult {
+	httpReq, err := http.NewRequest(req.method, baseURL+req.uri, nil)
+	if err != nil {
+		return replayResult{key: req.key, err: err}
+	}
+	start := time.Now()
+	res, err := client.Do(httpReq)
+	if err != nil {
+		return replayResult{key: req.key, err: err}
+	}
+	defer res.Body.Close()
+	_, err = io.Copy(io.Discard, res.Body)
+	return replayResult{key: req.key, status: res.StatusCode, matched: res.StatusCode == req.status, err: err, latency: time.Since(start)}
+}
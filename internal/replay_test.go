@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayer_Replay(t *testing.T) {
+	var mu sync.Mutex
+	requested := map[string][]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		uid := r.URL.Query().Get("uid")
+		requested[uid] = append(requested[uid], r.URL.RequestURI())
+		mu.Unlock()
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /items?uid=a&p=1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:00 +0900\treq:GET /items?uid=b&p=1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:POST /items?uid=a HTTP/2.0\tstatus:201\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /items?uid=a&p=2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n" +
+		"time:01/Jan/2023:12:00:02 +0900\treq:GET /missing?uid=b HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n" +
+		"time:01/Jan/2023:12:00:02 +0900\treq:GET /items?uid=a&p=3 HTTP/2.0\tstatus:500\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n")
+	logReader, _ := log.NewLTSVReader(stdin, log.LTSVReadOpt{TimeFormat: "02/Jan/2006:15:04:05 -0700"})
+
+	p := NewReplayer()
+	result, err := p.Replay(logReader, ReplayOption{BaseURL: ts.URL + "/", Methods: []string{"GET"}, Speed: 10, Concurrency: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GET /items", "GET /missing"}, result.Endpoints)
+	assert.Equal(t, map[string]int{"GET /items": 4, "GET /missing": 1}, result.Count)
+	assert.Equal(t, map[string]int{"GET /items": 4}, result.Success)
+	assert.Equal(t, map[string]int{"GET /items": 3}, result.StatusMatch)
+	assert.Equal(t, 2, result.Users)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Len(t, result.Latencies["GET /items"], 4)
+	assert.GreaterOrEqual(t, result.Elapsed, 200*time.Millisecond) // the last requests are 2 seconds later at 10x speed
+	assert.Equal(t, []string{"/items?uid=a&p=1", "/items?uid=a&p=2", "/items?uid=a&p=3"}, requested["a"])
+	assert.Equal(t, []string{"/items?uid=b&p=1", "/missing?uid=b"}, requested["b"])
+}
+
+func TestReplayer_Replay_error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close() // refuse connections
+
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /items HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n")
+	logReader, _ := log.NewLTSVReader(stdin, log.LTSVReadOpt{TimeFormat: "02/Jan/2006:15:04:05 -0700"})
+
+	p := NewReplayer()
+	result, err := p.Replay(logReader, ReplayOption{BaseURL: ts.URL, Methods: []string{"GET"}, Concurrency: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"GET /items": 1}, result.Count)
+	assert.Equal(t, map[string]int{"GET /items": 1}, result.Errors)
+	assert.Empty(t, result.Latencies["GET /items"])
+}
+
+func TestReplayer_Replay_redirect(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.Redirect(w, r, "/home", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /login HTTP/2.0\tstatus:302\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n")
+	logReader, _ := log.NewLTSVReader(stdin, log.LTSVReadOpt{TimeFormat: "02/Jan/2006:15:04:05 -0700"})
+
+	p := NewReplayer()
+	result, err := p.Replay(logReader, ReplayOption{BaseURL: ts.URL, Methods: []string{"GET"}, Concurrency: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GET /login"}, result.Endpoints)
+	assert.Equal(t, map[string]int{"GET /login": 1}, result.Success)
+	assert.Equal(t, map[string]int{"GET /login": 1}, result.StatusMatch)
+}
+
+func TestReplayer_Replay_workers(t *testing.T) {
+	var mu sync.Mutex
+	requested := make([]string, 0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.RequestURI())
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /items?uid=a&p=1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /items?uid=b&p=1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:02 +0900\treq:GET /items?uid=a&p=2 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n" +
+		"time:01/Jan/2023:12:00:03 +0900\treq:GET /items?uid=c&p=1 HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635C26725F02560303\tuidgot:-\n")
+	logReader, _ := log.NewLTSVReader(stdin, log.LTSVReadOpt{TimeFormat: "02/Jan/2006:15:04:05 -0700"})
+
+	p := NewReplayer()
+	result, err := p.Replay(logReader, ReplayOption{BaseURL: ts.URL, Methods: []string{"GET"}, Concurrency: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Users)
+	// a single worker replays the users one by one in order of their first requests
+	assert.Equal(t, []string{"/items?uid=a&p=1", "/items?uid=a&p=2", "/items?uid=b&p=1", "/items?uid=c&p=1"}, requested)
+}
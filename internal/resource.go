@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+	"golang.org/x/exp/maps"
+)
+
+type ResourceProfiler struct {
+}
+
+func NewResourceProfiler() *ResourceProfiler {
+	return &ResourceProfiler{}
+}
+
+// Profile links the endpoints sharing a named capture group, and counts reads (GET, HEAD) and writes
+// (POST, PUT, PATCH, DELETE) per value of the group. The log entries are expected to be in chronological order.
+func (p *ResourceProfiler) Profile(reader *log.LTSVReader) (*Resource, error) {
+	result := &Resource{
+		Endpoints: make(map[string]map[string]int),
+		IDs:       make(map[string]map[string]*ResourceID),
+	}
+
+	var entry log.LogEntry
+	for reader.Read() {
+		_, err := reader.Parse(&entry)
+		if err != nil {
+			if err == log.Filtered {
+				continue
+			}
+			return nil, err
+		}
+		if entry.MatchedGroup == nil {
+			continue
+		}
+		isWrite := slices.Contains(writeMethods, entry.Method)
+		if !isWrite && entry.Method != "GET" && entry.Method != "HEAD" {
+			continue
+		}
+
+		_, path, _ := log.ParseReq(entry.Req)
+		subMatches := entry.MatchedGroup.FindStringSubmatch(path)
+		for i, name := range entry.MatchedGroup.SubexpNames() {
+			if i == 0 || name == "" {
+				continue
+			}
+			if _, ok := result.Endpoints[name]; !ok {
+				result.Endpoints[name] = map[string]int{}
+				result.IDs[name] = map[string]*ResourceID{}
+			}
+			result.Endpoints[name][entry.Key()] += 1
+			id, ok := result.IDs[name][subMatches[i]]
+			if !ok {
+				id = &ResourceID{ID: subMatches[i]}
+				result.IDs[name][subMatches[i]] = id
+			}
+			if isWrite {
+				id.write(entry.Time)
+			} else {
+				id.read(entry.Time)
+			}
+		}
+	}
+
+	result.Names = maps.Keys(result.Endpoints)
+	slices.Sort(result.Names)
+	return result, nil
+}
+
+// Resource is the reads and writes of each resource, which is identified by the name of a capture group
+type Resource struct {
+	Names     []string
+	Endpoints map[string]map[string]int         // name -> endpoint -> count
+	IDs       map[string]map[string]*ResourceID // name -> value of the capture group -> stats
+}
+
+// SortedIDs returns the IDs of the resource in descending order of the requests
+func (r *Resource) SortedIDs(name string) []*ResourceID {
+	ids := maps.Values(r.IDs[name])
+	slices.SortFunc(ids, func(a, b *ResourceID) int {
+		if a.Reads+a.Writes != b.Reads+b.Writes {
+			return cmp.Compare(b.Reads+b.Writes, a.Reads+a.Writes)
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return ids
+}
+
+// WriteToRead returns the time between a write and the next read of all IDs of the resource in ascending order
+func (r *Resource) WriteToRead(name string) []time.Duration {
+	durations := make([]time.Duration, 0)
+	for _, id := range r.IDs[name] {
+		durations = append(durations, id.WriteToRead...)
+	}
+	slices.Sort(durations)
+	return durations
+}
+
+// ResourceID is the reads and writes of a resource ID
+type ResourceID struct {
+	ID          string
+	Reads       int
+	Writes      int
+	WriteToRead []time.Duration // time between each write and the next read. writes never read afterward are not included
+	pending     []time.Time     // writes not read yet
+}
+
+func (r *ResourceID) write(t time.Time) {
+	r.Writes++
+	r.pending = append(r.pending, t)
+}
+
+func (r *ResourceID) read(t time.Time) {
+	r.Reads++
+	for _, w := range r.pending {
+		r.WriteToRead = append(r.WriteToRead, t.Sub(w))
+	}
+	r.pending = r.pending[:0]
+}
+
+// DurationQuantile returns the q-quantile of the sorted durations by the nearest-rank method
+func DurationQuantile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(q * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceProfiler_Profile(t *testing.T) {
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /api/isu/a HTTP/2.0\tstatus:200\tuidset:uid=A\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:POST /api/condition/a HTTP/2.0\tstatus:202\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:02 +0900\treq:POST /api/condition/a HTTP/2.0\tstatus:202\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:05 +0900\treq:GET /api/isu/a HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:06 +0900\treq:GET /api/isu/b/graph HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n" +
+		"time:01/Jan/2023:12:00:07 +0900\treq:DELETE /api/isu/b/graph HTTP/2.0\tstatus:204\tuidset:-\tuidgot:uid=A\n" + // never read afterward
+		"time:01/Jan/2023:12:00:08 +0900\treq:OPTIONS /api/isu/b HTTP/2.0\tstatus:204\tuidset:-\tuidgot:uid=A\n" + // neither read nor write
+		"time:01/Jan/2023:12:00:09 +0900\treq:GET /api/users/1 HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=A\n") // unnamed group
+	logReader, err := log.NewLTSVReader(stdin, log.LTSVReadOpt{MatchingGroups: []string{
+		"^/api/isu/(?P<jia_isu_uuid>[^/]+)$", "^/api/isu/(?P<jia_isu_uuid>[^/]+)/graph$", "^/api/condition/(?P<jia_isu_uuid>[^/]+)$", "^/api/users/([^/]+)$",
+	}})
+	require.NoError(t, err)
+
+	resource, err := NewResourceProfiler().Profile(logReader)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jia_isu_uuid"}, resource.Names)
+	assert.Equal(t, map[string]int{
+		"GET ^/api/isu/(?P<jia_isu_uuid>[^/]+)$":          2,
+		"GET ^/api/isu/(?P<jia_isu_uuid>[^/]+)/graph$":    1,
+		"DELETE ^/api/isu/(?P<jia_isu_uuid>[^/]+)/graph$": 1,
+		"POST ^/api/condition/(?P<jia_isu_uuid>[^/]+)$":   2,
+	}, resource.Endpoints["jia_isu_uuid"])
+
+	ids := resource.SortedIDs("jia_isu_uuid")
+	require.Len(t, ids, 2)
+	assert.Equal(t, "a", ids[0].ID)
+	assert.Equal(t, 2, ids[0].Reads)
+	assert.Equal(t, 2, ids[0].Writes)
+	assert.Equal(t, []time.Duration{4 * time.Second, 3 * time.Second}, ids[0].WriteToRead)
+	assert.Equal(t, "b", ids[1].ID)
+	assert.Equal(t, 1, ids[1].Reads)
+	assert.Equal(t, 1, ids[1].Writes)
+	assert.Empty(t, ids[1].WriteToRead)
+	assert.Equal(t, []time.Duration{3 * time.Second, 4 * time.Second}, resource.WriteToRead("jia_isu_uuid"))
+}
+
+func TestDurationQuantile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	assert.Equal(t, time.Duration(0), DurationQuantile(nil, 0.5))
+	assert.Equal(t, time.Duration(1), DurationQuantile(sorted, 0))
+	assert.Equal(t, time.Duration(5), DurationQuantile(sorted, 0.5))
+	assert.Equal(t, time.Duration(9), DurationQuantile(sorted, 0.9))
+	assert.Equal(t, time.Duration(10), DurationQuantile(sorted, 0.99))
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"cmp"
+	"math"
 	"slices"
 	"strings"
 	"time"
@@ -17,6 +18,35 @@ type ScenarioProfiler struct {
 type ScenarioOption struct {
 	MatchingGroups []string
 	TimeFormat     string
+	SessionOption
+	IncompleteSessionOption
+	// Similarity is the threshold from 0 to 1 to cluster similar scenarios by ClusterScenarios. Zero disables the clustering.
+	Similarity float64
+}
+
+// DefaultStartNearEndRatio is the ratio used when StartNearEndRatio is zero
+const DefaultStartNearEndRatio = 0.05
+
+// IncompleteSessionOption is the policy to drop sessions which are cut off by the beginning or the end of the log.
+// Zero durations disable the rules.
+type IncompleteSessionOption struct {
+	// StartNearEnd drops sessions that start within the duration before the end of the log
+	StartNearEnd time.Duration
+	// StartNearEndRatio drops sessions that start within the ratio of the period of the log before the end of the log.
+	// Zero means DefaultStartNearEndRatio and a negative ratio disables the rule.
+	StartNearEndRatio float64
+	// ActiveAtEnd drops sessions whose last request is within the duration before the end of the log
+	ActiveAtEnd time.Duration
+	// StartNearBegin drops sessions that start within the duration after the beginning of the log without a new uid set,
+	// which are likely to have started before the log began
+	StartNearBegin time.Duration
+}
+
+// IncompleteSessions is the number of sessions dropped by each rule of IncompleteSessionOption
+type IncompleteSessions struct {
+	StartNearEnd   int
+	ActiveAtEnd    int
+	StartNearBegin int
 }
 
 type ScenarioStruct struct {
@@ -25,19 +55,47 @@ type ScenarioStruct struct {
 	FirstReq int
 	LastReq  int
 	Pattern  *pattern.Node
+	Sessions []ScenarioSession
+	Members  int // number of the distinct scenarios clustered into the scenario
+}
+
+// ScenarioSession is a session of a user which follows the scenario
+type ScenarioSession struct {
+	Endpoints []string
+	Times     []int // milliseconds from the beginning of the log
+}
+
+// Iterations returns the observed counts of each group of the pattern in the order of Pattern.Groups. See pattern.Node.Iterations.
+// Sessions which don't match the pattern are ignored.
+func (s ScenarioStruct) Iterations() [][]int {
+	result := make([][]int, len(s.Pattern.Groups()))
+	for _, session := range s.Sessions {
+		iterations, ok := s.Pattern.Iterations(session.Endpoints)
+		if !ok {
+			continue
+		}
+		for i, counts := range iterations {
+			result[i] = append(result[i], counts...)
+		}
+	}
+	return result
 }
 
 func NewScenarioProfiler() *ScenarioProfiler {
 	return &ScenarioProfiler{}
 }
 
-func (p *ScenarioProfiler) Profile(reader *log.LTSVReader) ([]ScenarioStruct, error) {
+func (p *ScenarioProfiler) Profile(reader *log.LTSVReader, opt ScenarioOption) ([]ScenarioStruct, IncompleteSessions, error) {
 	var result = map[string]*pattern.Node{}
+	sequences := map[string][]string{}
+	times := map[string][]int{}
 	endpoints := map[string]struct{}{}
 	intToEndpoint := map[int]string{}
 	endpointToInt := map[string]int{}
 	firstCalls := map[string]int{}
 	lastCalls := map[string]int{}
+	setNewUid := map[string]bool{}
+	sessionizer := NewSessionizer(opt.SessionOption)
 
 	i := 0
 	var startTime time.Time
@@ -49,7 +107,7 @@ func (p *ScenarioProfiler) Profile(reader *log.LTSVReader) ([]ScenarioStruct, er
 			if err == log.Filtered {
 				continue
 			}
-			return nil, err
+			return nil, IncompleteSessions{}, err
 		}
 
 		k := entry.Key()
@@ -68,43 +126,61 @@ func (p *ScenarioProfiler) Profile(reader *log.LTSVReader) ([]ScenarioStruct, er
 			i++
 		}
 
-		if entry.Uid != "" {
-			if entry.SetNewUid {
-				result[entry.Uid] = &pattern.Node{}
-				firstCalls[entry.Uid] = reqTimeSec
+		if session, isNew := sessionizer.Session(entry); session != "" {
+			if isNew {
+				result[session] = &pattern.Node{}
+				firstCalls[session] = reqTimeSec
+				setNewUid[session] = entry.SetNewUid
 			}
-			result[entry.Uid].Append(k)
-			lastCalls[entry.Uid] = reqTimeSec
+			result[session].Append(k)
+			sequences[session] = append(sequences[session], k)
+			times[session] = append(times[session], reqTimeSec)
+			lastCalls[session] = reqTimeSec
 		}
 	}
-	period := endTime.Sub(startTime).Milliseconds()
-	latestFirstCall := int64(float64(period) * 0.95)
-	for uid, firstCall := range firstCalls {
-		if firstCall > int(latestFirstCall) {
-			delete(result, uid)
-			delete(firstCalls, uid)
-			delete(lastCalls, uid)
+	period := int(endTime.Sub(startTime).Milliseconds())
+	startNearEnd := int(opt.StartNearEnd.Milliseconds())
+	if ratio := cmp.Or(opt.StartNearEndRatio, DefaultStartNearEndRatio); ratio > 0 {
+		startNearEnd = max(startNearEnd, int(float64(period)*ratio))
+	}
+	var dropped IncompleteSessions
+	for session, firstCall := range firstCalls {
+		switch {
+		case startNearEnd > 0 && period-firstCall < startNearEnd:
+			dropped.StartNearEnd++
+		case opt.ActiveAtEnd > 0 && period-lastCalls[session] < int(opt.ActiveAtEnd.Milliseconds()):
+			dropped.ActiveAtEnd++
+		case opt.StartNearBegin > 0 && !setNewUid[session] && firstCall < int(opt.StartNearBegin.Milliseconds()):
+			dropped.StartNearBegin++
+		default:
+			continue
 		}
+		delete(result, session)
+		delete(firstCalls, session)
+		delete(lastCalls, session)
 	}
 
 	scenarios := map[string]ScenarioStruct{}
-	for uid, scenario := range result {
+	for session, scenario := range result {
 		if s, ok := scenarios[scenario.String(true)]; ok {
 			s.Count += 1
-			if firstCalls[uid] < s.FirstReq {
-				s.FirstReq = firstCalls[uid]
+			if firstCalls[session] < s.FirstReq {
+				s.FirstReq = firstCalls[session]
 			}
-			if s.LastReq < lastCalls[uid] {
-				s.LastReq = lastCalls[uid]
+			if s.LastReq < lastCalls[session] {
+				s.LastReq = lastCalls[session]
 			}
+			s.Sessions = append(s.Sessions, ScenarioSession{Endpoints: sequences[session], Times: times[session]})
 			scenarios[scenario.String(true)] = s
 		} else {
 			scenarios[scenario.String(true)] = ScenarioStruct{
 				Hash:     scenario.String(true),
 				Count:    1,
-				FirstReq: firstCalls[uid],
-				LastReq:  lastCalls[uid],
+				FirstReq: firstCalls[session],
+				LastReq:  lastCalls[session],
 				Pattern:  scenario,
+				Sessions: []ScenarioSession{{Endpoints: sequences[session], Times: times[session]}},
+				Members:  1,
 			}
 		}
 	}
@@ -125,6 +201,8 @@ func (p *ScenarioProfiler) Profile(reader *log.LTSVReader) ([]ScenarioStruct, er
 					FirstReq: min(s.FirstReq, t.FirstReq),
 					LastReq:  max(s.LastReq, t.LastReq),
 					Pattern:  p,
+					Sessions: append(t.Sessions, s.Sessions...),
+					Members:  t.Members + s.Members,
 				}
 				match = true
 				break
@@ -135,6 +213,10 @@ func (p *ScenarioProfiler) Profile(reader *log.LTSVReader) ([]ScenarioStruct, er
 		}
 	}
 
+	if opt.Similarity > 0 {
+		tt = ClusterScenarios(tt, opt.Similarity)
+	}
+
 	slices.SortFunc(tt, func(a, b ScenarioStruct) int {
 		if a.FirstReq != b.FirstReq {
 			return cmp.Compare(a.FirstReq, b.FirstReq)
@@ -145,5 +227,110 @@ func (p *ScenarioProfiler) Profile(reader *log.LTSVReader) ([]ScenarioStruct, er
 		return strings.Compare(b.Pattern.String(true), a.Pattern.String(true))
 	})
 
-	return tt, nil
+	return tt, dropped, nil
+}
+
+// ScenarioEdge is a transition between the leaves of the pattern. From and To are the indices of the leaves in the order of pattern.Flatten.
+type ScenarioEdge struct {
+	From int
+	To   int
+}
+
+// ScenarioTiming is the timing statistics of the sessions of a scenario
+type ScenarioTiming struct {
+	Duration  DurationStats                  // duration from the first request to the last request of a session
+	Requests  RequestStats                   // number of the requests of a session
+	ThinkTime map[ScenarioEdge]DurationStats // time between consecutive requests by the edge of the pattern
+	// ThinkTimeBefore is the time from the previous request by the leaf of the pattern which is requested
+	ThinkTimeBefore map[int]DurationStats
+}
+
+type DurationStats struct {
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+type RequestStats struct {
+	Total int
+	Mean  float64
+	P50   int
+	P90   int
+	Max   int
+}
+
+// NewDurationStats returns the statistics of the durations. The durations are sorted in place.
+func NewDurationStats(durations []time.Duration) DurationStats {
+	if len(durations) == 0 {
+		return DurationStats{}
+	}
+	slices.Sort(durations)
+	var sum time.Duration
+	for _, d := range durations {
+		sum += d
+	}
+	return DurationStats{
+		Count: len(durations),
+		Mean:  sum / time.Duration(len(durations)),
+		P50:   DurationQuantile(durations, 0.5),
+		P90:   DurationQuantile(durations, 0.9),
+		P99:   DurationQuantile(durations, 0.99),
+		Max:   durations[len(durations)-1],
+	}
+}
+
+// Timing calculates the timing statistics of the sessions.
+// Think times are collected only from the sessions which match the pattern.
+func (s ScenarioStruct) Timing() ScenarioTiming {
+	durations := make([]time.Duration, 0, len(s.Sessions))
+	requests := make([]int, 0, len(s.Sessions))
+	thinkTimes := map[ScenarioEdge][]time.Duration{}
+	thinkTimesBefore := map[int][]time.Duration{}
+	total := 0
+	for _, session := range s.Sessions {
+		n := len(session.Times)
+		if n == 0 {
+			continue
+		}
+		durations = append(durations, time.Duration(session.Times[n-1]-session.Times[0])*time.Millisecond)
+		requests = append(requests, n)
+		total += n
+
+		leaves, ok := s.Pattern.LeafIndices(session.Endpoints)
+		if !ok {
+			continue
+		}
+		for i := 1; i < n; i++ {
+			e := ScenarioEdge{From: leaves[i-1], To: leaves[i]}
+			d := time.Duration(session.Times[i]-session.Times[i-1]) * time.Millisecond
+			thinkTimes[e] = append(thinkTimes[e], d)
+			thinkTimesBefore[leaves[i]] = append(thinkTimesBefore[leaves[i]], d)
+		}
+	}
+
+	timing := ScenarioTiming{
+		Duration:        NewDurationStats(durations),
+		ThinkTime:       make(map[ScenarioEdge]DurationStats, len(thinkTimes)),
+		ThinkTimeBefore: make(map[int]DurationStats, len(thinkTimesBefore)),
+	}
+	if len(requests) > 0 {
+		slices.Sort(requests)
+		timing.Requests = RequestStats{
+			Total: total,
+			Mean:  float64(total) / float64(len(requests)),
+			P50:   requests[max(int(math.Ceil(0.5*float64(len(requests)))), 1)-1],
+			P90:   requests[max(int(math.Ceil(0.9*float64(len(requests)))), 1)-1],
+			Max:   requests[len(requests)-1],
+		}
+	}
+	for e, ds := range thinkTimes {
+		timing.ThinkTime[e] = NewDurationStats(ds)
+	}
+	for leaf, ds := range thinkTimesBefore {
+		timing.ThinkTimeBefore[leaf] = NewDurationStats(ds)
+	}
+	return timing
 }
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/haijima/stool/internal/pattern"
+)
+
+// ClusterScenarios clusters the scenarios whose flattened endpoint sequences are similar by pattern.Similarity.
+// Scenarios are visited in descending order of the count, and each of them joins the first cluster whose representative
+// is similar at least the threshold, otherwise it becomes the representative of a new cluster.
+// The pattern of the representative is merged with the ones of the members by pattern.MergeOptional,
+// so the steps skipped by some members become optional and the substituted steps become alternatives.
+func ClusterScenarios(scenarios []ScenarioStruct, threshold float64) []ScenarioStruct {
+	ss := slices.Clone(scenarios)
+	slices.SortStableFunc(ss, func(a, b ScenarioStruct) int {
+		if a.Count != b.Count {
+			return cmp.Compare(b.Count, a.Count)
+		}
+		return strings.Compare(a.Hash, b.Hash)
+	})
+
+	clusters := make([]ScenarioStruct, 0)
+	flats := make([][]string, 0)
+	for _, s := range ss {
+		flat := pattern.Flatten([]pattern.Node{*s.Pattern}, make([]string, s.Pattern.Leaves()))
+		joined := false
+		for i, c := range clusters {
+			if pattern.Similarity(flats[i], flat) < threshold {
+				continue
+			}
+			p := pattern.MergeOptional(c.Pattern.Children(), s.Pattern.Children())
+			clusters[i] = ScenarioStruct{
+				Hash:     p.String(true),
+				Count:    c.Count + s.Count,
+				FirstReq: min(c.FirstReq, s.FirstReq),
+				LastReq:  max(c.LastReq, s.LastReq),
+				Pattern:  p,
+				Sessions: append(slices.Clip(c.Sessions), s.Sessions...),
+				Members:  c.Members + max(s.Members, 1),
+			}
+			joined = true
+			break
+		}
+		if !joined {
+			s.Members = max(s.Members, 1)
+			clusters = append(clusters, s)
+			flats = append(flats, flat)
+		}
+	}
+	return clusters
+}
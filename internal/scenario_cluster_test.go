@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/haijima/stool/internal/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestScenario(t *testing.T, s string, count int, firstReq int) ScenarioStruct {
+	t.Helper()
+	p, err := pattern.Parse(s)
+	assert.NoError(t, err)
+	return ScenarioStruct{Hash: s, Count: count, FirstReq: firstReq, LastReq: firstReq, Pattern: p, Members: 1}
+}
+
+func TestClusterScenarios(t *testing.T) {
+	scenarios := []ScenarioStruct{
+		newTestScenario(t, "POST /login -> GET /items -> GET /favicon.ico -> POST /cart", 2, 3000),
+		newTestScenario(t, "POST /login -> GET /items -> POST /cart", 10, 1000),
+		newTestScenario(t, "POST /login -> POST /cart", 3, 2000),
+		newTestScenario(t, "GET /admin", 1, 0),
+	}
+
+	clusters := ClusterScenarios(scenarios, 0.6)
+
+	assert.Equal(t, 2, len(clusters))
+	assert.Equal(t, "POST /login -> GET /items? -> GET /favicon.ico? -> POST /cart", clusters[0].Hash)
+	assert.Equal(t, "POST /login -> GET /items? -> GET /favicon.ico? -> POST /cart", clusters[0].Pattern.String(true))
+	assert.Equal(t, 15, clusters[0].Count)
+	assert.Equal(t, 3, clusters[0].Members)
+	assert.Equal(t, 1000, clusters[0].FirstReq)
+	assert.Equal(t, 3000, clusters[0].LastReq)
+	assert.Equal(t, "GET /admin", clusters[1].Hash)
+	assert.Equal(t, 1, clusters[1].Members)
+}
+
+func TestClusterScenarios_threshold(t *testing.T) {
+	scenarios := []ScenarioStruct{
+		newTestScenario(t, "POST /login -> GET /items -> POST /cart", 10, 1000),
+		newTestScenario(t, "POST /login -> POST /cart", 3, 2000),
+	}
+
+	clusters := ClusterScenarios(scenarios, 0.9)
+
+	assert.Equal(t, 2, len(clusters))
+	assert.Equal(t, 1, clusters[0].Members)
+	assert.Equal(t, 1, clusters[1].Members)
+}
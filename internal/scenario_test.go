@@ -3,6 +3,7 @@ package internal
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/haijima/stool/internal/log"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,7 @@ func TestScenarioProfiler_Profile(t *testing.T) {
 		MatchingGroups: []string{"^/api/user/[^\\/]+$", "^/api/group/[^\\/]+$"},
 	})
 
-	scenarios, err := p.Profile(logReader)
+	scenarios, _, err := p.Profile(logReader, ScenarioOption{})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, scenarios)
@@ -31,4 +32,82 @@ func TestScenarioProfiler_Profile(t *testing.T) {
 	assert.Equal(t, 5000, scenarios[1].FirstReq)
 	assert.Equal(t, 6000, scenarios[1].LastReq)
 	assert.Equal(t, "(GET /)*", scenarios[1].Pattern.String(true))
+	assert.Equal(t, []ScenarioSession{{Endpoints: []string{"GET /", "GET /"}, Times: []int{5000, 6000}}}, scenarios[1].Sessions)
+	assert.Equal(t, [][]int{{2}}, scenarios[1].Iterations())
+	timing := scenarios[1].Timing()
+	assert.Equal(t, DurationStats{Count: 1, Mean: time.Second, P50: time.Second, P90: time.Second, P99: time.Second, Max: time.Second}, timing.Duration)
+	assert.Equal(t, RequestStats{Total: 2, Mean: 2, P50: 2, P90: 2, Max: 2}, timing.Requests)
+	assert.Equal(t, map[ScenarioEdge]DurationStats{{From: 0, To: 0}: timing.Duration}, timing.ThinkTime)
+	assert.Equal(t, map[int]DurationStats{0: timing.Duration}, timing.ThinkTimeBefore)
+}
+
+func TestScenarioProfiler_Profile_incompleteSessions(t *testing.T) {
+	p := NewScenarioProfiler()
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /a HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:02 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635C26725F02560303\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:03 +0900\treq:GET /c HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635C26725F02560303\n" +
+		"time:01/Jan/2023:12:00:10 +0900\treq:GET /b HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n")
+	logReader, _ := log.NewLTSVReader(stdin, log.LTSVReadOpt{TimeFormat: "02/Jan/2006:15:04:05 -0700"})
+
+	scenarios, dropped, err := p.Profile(logReader, ScenarioOption{
+		SessionOption:           SessionOption{StartOnUidGot: true},
+		IncompleteSessionOption: IncompleteSessionOption{ActiveAtEnd: 3 * time.Second, StartNearBegin: 5 * time.Second},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, IncompleteSessions{ActiveAtEnd: 1, StartNearBegin: 1}, dropped)
+	assert.Equal(t, 1, len(scenarios))
+	assert.Equal(t, "POST /login -> GET /c", scenarios[0].Hash)
+}
+
+func TestScenarioProfiler_Profile_mergedMembers(t *testing.T) {
+	p := NewScenarioProfiler()
+	stdin := bytes.NewBufferString("time:01/Jan/2023:12:00:00 +0900\treq:GET /a HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:01 +0900\treq:GET /b HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0F528CA635B26685F02030303\n" +
+		"time:01/Jan/2023:12:00:02 +0900\treq:GET /a HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:03 +0900\treq:GET /b HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n" +
+		"time:01/Jan/2023:12:00:04 +0900\treq:GET /b HTTP/2.0\tstatus:200\tuidset:-\tuidgot:uid=0B00A8C0FA28CA635B26685F02040303\n")
+	logReader, _ := log.NewLTSVReader(stdin, log.LTSVReadOpt{TimeFormat: "02/Jan/2006:15:04:05 -0700"})
+
+	scenarios, _, err := p.Profile(logReader, ScenarioOption{IncompleteSessionOption: IncompleteSessionOption{StartNearEndRatio: -1}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(scenarios))
+	assert.Equal(t, 2, scenarios[0].Count)
+	assert.Equal(t, 2, scenarios[0].Members)
+}
+
+func TestScenarioProfiler_Profile_zeroThresholds(t *testing.T) {
+	p := NewScenarioProfiler()
+	lines := "time:01/Jan/2023:12:00:00 +0900\treq:POST /login HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0F528CA635B26685F02030303\tuidgot:-\n" +
+		"time:01/Jan/2023:12:00:10 +0900\treq:GET /a HTTP/2.0\tstatus:200\tuidset:uid=0B00A8C0FA28CA635B26685F02040303\tuidgot:-\n"
+
+	tests := []struct {
+		name    string
+		opt     IncompleteSessionOption
+		dropped IncompleteSessions
+		want    int
+	}{
+		{name: "default ratio", opt: IncompleteSessionOption{}, dropped: IncompleteSessions{StartNearEnd: 1}, want: 1},
+		{name: "no rule", opt: IncompleteSessionOption{StartNearEndRatio: -1}, dropped: IncompleteSessions{}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logReader, _ := log.NewLTSVReader(bytes.NewBufferString(lines), log.LTSVReadOpt{TimeFormat: "02/Jan/2006:15:04:05 -0700"})
+
+			scenarios, dropped, err := p.Profile(logReader, ScenarioOption{IncompleteSessionOption: tt.opt})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.dropped, dropped)
+			assert.Equal(t, tt.want, len(scenarios))
+		})
+	}
+}
+
+func TestNewDurationStats(t *testing.T) {
+	stats := NewDurationStats([]time.Duration{4 * time.Second, time.Second, 2 * time.Second, 3 * time.Second})
+
+	assert.Equal(t, DurationStats{Count: 4, Mean: 2500 * time.Millisecond, P50: 2 * time.Second, P90: 4 * time.Second, P99: 4 * time.Second, Max: 4 * time.Second}, stats)
+	assert.Equal(t, DurationStats{}, NewDurationStats(nil))
 }
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+)
+
+// SessionOption is the option to split the requests of each user into sessions
+type SessionOption struct {
+	// IdleTimeout is the maximum gap between requests in a session. Zero means no timeout.
+	IdleTimeout time.Duration
+	// StartOnUidGot starts a session for a user who is first seen via uidgot (e.g. whose cookie predates the log).
+	StartOnUidGot bool
+}
+
+// Sessionizer assigns each log entry to a session of its user.
+// A new session starts when a new uid is set, or when the user has been idle longer than IdleTimeout.
+type Sessionizer struct {
+	opt      SessionOption
+	sessions map[string]int
+	lastSeen map[string]time.Time
+}
+
+func NewSessionizer(opt SessionOption) *Sessionizer {
+	return &Sessionizer{
+		opt:      opt,
+		sessions: map[string]int{},
+		lastSeen: map[string]time.Time{},
+	}
+}
+
+// Session returns the ID of the session that the entry belongs to and whether the entry starts the session.
+// It returns an empty ID if the entry has no uid or its user is first seen via uidgot and StartOnUidGot is false.
+func (s *Sessionizer) Session(entry log.LogEntry) (string, bool) {
+	if entry.Uid == "" {
+		return "", false
+	}
+
+	n, seen := s.sessions[entry.Uid]
+	isNew := false
+	switch {
+	case entry.SetNewUid:
+		isNew = true
+	case !seen:
+		if !s.opt.StartOnUidGot {
+			return "", false
+		}
+		isNew = true
+	case s.opt.IdleTimeout > 0 && entry.Time.Sub(s.lastSeen[entry.Uid]) > s.opt.IdleTimeout:
+		isNew = true
+	}
+	if isNew {
+		n++
+		s.sessions[entry.Uid] = n
+	}
+	s.lastSeen[entry.Uid] = entry.Time
+
+	return entry.Uid + "#" + strconv.Itoa(n), isNew
+}
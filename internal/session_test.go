@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/haijima/stool/internal/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionizer_Session(t *testing.T) {
+	base := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(uid string, setNew bool, sec int) log.LogEntry {
+		return log.LogEntry{Uid: uid, SetNewUid: setNew, Time: base.Add(time.Duration(sec) * time.Second)}
+	}
+
+	tests := []struct {
+		name    string
+		opt     SessionOption
+		entries []log.LogEntry
+		want    []string
+		wantNew []bool
+	}{
+		{
+			name:    "new uid",
+			entries: []log.LogEntry{entry("a", true, 0), entry("a", false, 10), entry("a", true, 20)},
+			want:    []string{"a#1", "a#1", "a#2"},
+			wantNew: []bool{true, false, true},
+		},
+		{
+			name:    "no uid",
+			entries: []log.LogEntry{entry("", false, 0)},
+			want:    []string{""},
+			wantNew: []bool{false},
+		},
+		{
+			name:    "first seen via uidgot",
+			entries: []log.LogEntry{entry("a", false, 0), entry("a", false, 10)},
+			want:    []string{"", ""},
+			wantNew: []bool{false, false},
+		},
+		{
+			name:    "start on uidgot",
+			opt:     SessionOption{StartOnUidGot: true},
+			entries: []log.LogEntry{entry("a", false, 0), entry("a", false, 10)},
+			want:    []string{"a#1", "a#1"},
+			wantNew: []bool{true, false},
+		},
+		{
+			name:    "idle timeout",
+			opt:     SessionOption{IdleTimeout: 30 * time.Second},
+			entries: []log.LogEntry{entry("a", true, 0), entry("b", true, 10), entry("a", false, 30), entry("a", false, 61), entry("b", false, 50)},
+			want:    []string{"a#1", "b#1", "a#1", "a#2", "b#2"},
+			wantNew: []bool{true, true, false, true, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSessionizer(tt.opt)
+			for i, e := range tt.entries {
+				got, isNew := s.Session(e)
+				assert.Equal(t, tt.want[i], got)
+				assert.Equal(t, tt.wantNew[i], isNew)
+			}
+		})
+	}
+}
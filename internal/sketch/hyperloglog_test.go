@@ -0,0 +1,34 @@
+package sketch
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+	}{
+		{name: "empty", n: 0},
+		{name: "small", n: 100},
+		{name: "large", n: 100000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHyperLogLog(14)
+			for i := 0; i < tt.n; i++ {
+				h.Add(strconv.Itoa(i))
+				h.Add(strconv.Itoa(i)) // duplicates are not counted
+			}
+			assert.InDelta(t, tt.n, h.Count(), float64(tt.n)*3*h.StdError())
+		})
+	}
+}
+
+func TestHyperLogLog_StdError(t *testing.T) {
+	assert.InDelta(t, 0.008125, NewHyperLogLog(14).StdError(), 1e-6)
+	assert.InDelta(t, 0.26, NewHyperLogLog(1).StdError(), 1e-6) // clamped to 4
+}
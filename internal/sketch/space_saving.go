@@ -0,0 +1,114 @@
+package sketch
+
+import (
+	"cmp"
+	"container/heap"
+	"slices"
+	"strings"
+)
+
+// SpaceSaving tracks the most frequent items of a stream in a fixed number of counters.
+// When all counters are in use, a new item takes over the counter with the smallest count,
+// so a count may be overestimated by at most its Error. (Metwally et al., 2005)
+type SpaceSaving struct {
+	capacity int
+	n        int
+	evicted  bool
+	counters map[string]*Counter
+	heap     counterHeap
+}
+
+// Counter is a monitored item. The true count of the item is between Count-Error and Count.
+type Counter struct {
+	Item  string
+	Count int
+	Error int
+	index int
+}
+
+// NewSpaceSaving returns a SpaceSaving that monitors up to capacity items
+func NewSpaceSaving(capacity int) *SpaceSaving {
+	return &SpaceSaving{
+		capacity: max(capacity, 1),
+		counters: make(map[string]*Counter, capacity),
+		heap:     make(counterHeap, 0, capacity),
+	}
+}
+
+// Add counts an occurrence of the item
+func (s *SpaceSaving) Add(item string) {
+	s.n++
+	if c, ok := s.counters[item]; ok {
+		c.Count++
+		heap.Fix(&s.heap, c.index)
+		return
+	}
+	if len(s.heap) < s.capacity {
+		c := &Counter{Item: item, Count: 1}
+		s.counters[item] = c
+		heap.Push(&s.heap, c)
+		return
+	}
+	c := s.heap[0]
+	s.evicted = true
+	delete(s.counters, c.Item)
+	c.Item, c.Error = item, c.Count
+	c.Count++
+	s.counters[item] = c
+	heap.Fix(&s.heap, c.index)
+}
+
+// N returns the number of added items
+func (s *SpaceSaving) N() int {
+	return s.n
+}
+
+// Top returns up to k counters in descending order of their counts
+func (s *SpaceSaving) Top(k int) []Counter {
+	top := make([]Counter, 0, len(s.heap))
+	for _, c := range s.heap {
+		top = append(top, *c)
+	}
+	slices.SortFunc(top, func(a, b Counter) int {
+		if a.Count != b.Count {
+			return cmp.Compare(b.Count, a.Count)
+		}
+		return strings.Compare(a.Item, b.Item)
+	})
+	if len(top) > k {
+		top = top[:k]
+	}
+	return top
+}
+
+// MaxError returns the upper bound of the overestimation of any count, which is at most N/capacity.
+// It also bounds the count of any item not monitored. It is 0 until a counter is taken over.
+func (s *SpaceSaving) MaxError() int {
+	if !s.evicted {
+		return 0
+	}
+	return s.heap[0].Count
+}
+
+type counterHeap []*Counter
+
+func (h counterHeap) Len() int           { return len(h) }
+func (h counterHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h counterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *counterHeap) Push(x any) {
+	c := x.(*Counter)
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *counterHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
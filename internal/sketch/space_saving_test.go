@@ -0,0 +1,54 @@
+package sketch
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpaceSaving(t *testing.T) {
+	s := NewSpaceSaving(3)
+	for _, item := range []string{"a", "b", "a", "c", "a", "b"} {
+		s.Add(item)
+	}
+
+	assert.Equal(t, 6, s.N())
+	assert.Equal(t, 0, s.MaxError())
+	assert.Equal(t, []Counter{{Item: "a", Count: 3}, {Item: "b", Count: 2}}, clearIndex(s.Top(2)))
+}
+
+func TestSpaceSaving_Evict(t *testing.T) {
+	s := NewSpaceSaving(2)
+	for _, item := range []string{"a", "a", "a", "b", "c"} {
+		s.Add(item)
+	}
+
+	// "c" takes over the counter of "b" and inherits its count as the error
+	assert.Equal(t, []Counter{{Item: "a", Count: 3}, {Item: "c", Count: 2, Error: 1}}, clearIndex(s.Top(5)))
+	assert.Equal(t, 2, s.MaxError())
+}
+
+func TestSpaceSaving_HeavyHitters(t *testing.T) {
+	s := NewSpaceSaving(10)
+	for i := 0; i < 10000; i++ {
+		if i%4 == 0 {
+			s.Add("hot")
+		} else {
+			s.Add(strconv.Itoa(i))
+		}
+	}
+
+	top := s.Top(1)
+	assert.Equal(t, "hot", top[0].Item)
+	assert.GreaterOrEqual(t, top[0].Count, 2500)
+	assert.LessOrEqual(t, top[0].Count-top[0].Error, 2500)
+	assert.LessOrEqual(t, s.MaxError(), s.N()/10)
+}
+
+func clearIndex(counters []Counter) []Counter {
+	for i := range counters {
+		counters[i].index = 0
+	}
+	return counters
+}
@@ -12,13 +12,14 @@ func NewTransitionProfiler() *TransitionProfiler {
 	return &TransitionProfiler{}
 }
 
-func (p *TransitionProfiler) Profile(reader *log.LTSVReader) (*Transition, error) {
+func (p *TransitionProfiler) Profile(reader *log.LTSVReader, opt TransitionOption) (*Transition, error) {
 	var result = map[string]map[string]int{}
 	result[""] = map[string]int{}
 	var lastVisit = map[string]string{}
 	var sum = map[string]int{}
 	endpoints := map[string]struct{}{}
 	endpoints[""] = struct{}{}
+	sessionizer := NewSessionizer(opt.SessionOption)
 
 	var entry log.LogEntry
 	for reader.Read() {
@@ -35,7 +36,15 @@ func (p *TransitionProfiler) Profile(reader *log.LTSVReader) (*Transition, error
 		endpoints[k] = struct{}{}
 		sum[k] += 1
 
-		if entry.Uid != "" {
+		if session, isNew := sessionizer.Session(entry); session != "" {
+			if lv := lastVisit[entry.Uid]; isNew && lv != "" {
+				// close the previous session of the user
+				if result[lv] == nil {
+					result[lv] = map[string]int{}
+				}
+				result[lv][""] += 1
+				lastVisit[entry.Uid] = ""
+			}
 			lv := lastVisit[entry.Uid]
 			if result[lv] == nil {
 				result[lv] = map[string]int{}
@@ -59,6 +68,7 @@ func (p *TransitionProfiler) Profile(reader *log.LTSVReader) (*Transition, error
 type TransitionOption struct {
 	MatchingGroups []string
 	TimeFormat     string
+	SessionOption
 }
 
 type Transition struct {
@@ -17,7 +17,7 @@ func TestTransitionProfiler_Profile(t *testing.T) {
 		MatchingGroups: []string{"^/api/user/[^\\/]+$", "^/api/group/[^\\/]+$"},
 	})
 
-	transition, err := p.Profile(logReader)
+	transition, err := p.Profile(logReader, TransitionOption{})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, transition)